@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Typed errors returned (wrapped in a StatusError) for non-2xx responses, check them with errors.Is
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrServer       = errors.New("server error")
+)
+
+// StatusError is returned when the API answers with a non-2xx status code
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string // error message from the response body, if any
+}
+
+func (e *StatusError) Error() string {
+	if e.Message != "" {
+		return fmt.Sprintf("%s %s: received status code %d: %s", e.Method, e.URL, e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("%s %s: received status code %d", e.Method, e.URL, e.StatusCode)
+}
+
+// Unwrap maps the status code onto ErrUnauthorized, ErrNotFound or ErrServer
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	}
+	return nil
+}
+
+// RetryPolicy sets how many times a failed GET request is retried and the wait before each retry.
+// The wait grows linearly with the attempt number.
+type RetryPolicy struct {
+	Retries int
+	Backoff time.Duration
+}
+
+// Client performs JSON requests against a REST API authenticated by an API key header (the *arr apps)
+type Client struct {
+	BaseURL    string // e.g. http://10.23.0.3:8989/api/v3
+	APIKey     string
+	KeyHeader  string // header carrying the API key, X-Api-Key by default
+	HTTPClient *http.Client
+	Retry      RetryPolicy
+}
+
+// NewClient creates a client sending apiKey in the X-Api-Key header
+func NewClient(baseURL, apiKey string, timeout time.Duration, retry RetryPolicy) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		APIKey:     apiKey,
+		KeyHeader:  "X-Api-Key",
+		HTTPClient: &http.Client{Timeout: timeout},
+		Retry:      retry,
+	}
+}
+
+// Get performs a GET request and decodes the JSON response into out
+func (c *Client) Get(path string, query url.Values, out any) error {
+	return c.Do(http.MethodGet, path, query, nil, out)
+}
+
+// Post sends body as JSON and decodes the JSON response into out (if not nil)
+func (c *Client) Post(path string, query url.Values, body, out any) error {
+	return c.Do(http.MethodPost, path, query, body, out)
+}
+
+// Put sends body as JSON and decodes the JSON response into out (if not nil)
+func (c *Client) Put(path string, query url.Values, body, out any) error {
+	return c.Do(http.MethodPut, path, query, body, out)
+}
+
+// Delete performs a DELETE request
+func (c *Client) Delete(path string, query url.Values) error {
+	return c.Do(http.MethodDelete, path, query, nil, nil)
+}
+
+// Do performs a request, retrying GET requests on network and server errors according to the retry policy
+func (c *Client) Do(method, path string, query url.Values, body, out any) error {
+	attempts := 1
+	if method == http.MethodGet {
+		attempts += c.Retry.Retries
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(c.Retry.Backoff * time.Duration(attempt-1))
+		}
+
+		err = c.do(method, path, query, body, out)
+		if err == nil || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// do performs a single request
+func (c *Client) do(method, path string, query url.Values, body, out any) error {
+	// Build the URL with query parameters
+	u, err := url.Parse(c.BaseURL + path)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+
+	// Encode the request body
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshalling request body: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	// Create the request
+	req, err := http.NewRequest(method, u.String(), reader)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	// Set the API key header
+	req.Header.Set(c.KeyHeader, c.APIKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// Perform the HTTP request
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error performing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read the response body
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &StatusError{
+			Method:     method,
+			URL:        u.Redacted(),
+			StatusCode: resp.StatusCode,
+			Message:    errorMessage(responseBody),
+		}
+	}
+
+	if out == nil || len(responseBody) == 0 {
+		return nil
+	}
+
+	// Parse the JSON response
+	if err := json.Unmarshal(responseBody, out); err != nil {
+		return fmt.Errorf("error unmarshalling response: %w", err)
+	}
+	return nil
+}
+
+// retryable reports whether a request failing with err is worth retrying
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return errors.Is(err, ErrServer)
+	}
+	// Network errors (timeouts, connection refused...) are retried, anything else is not
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// errorMessage extracts the error message from an *arr error response body.
+// The body is either {"message": "..."}, a list of validation failures or plain text.
+func errorMessage(body []byte) string {
+	var single struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &single) == nil && single.Message != "" {
+		return single.Message
+	}
+
+	var validation []struct {
+		PropertyName string `json:"propertyName"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if json.Unmarshal(body, &validation) == nil && len(validation) > 0 {
+		var messages []string
+		for _, v := range validation {
+			messages = append(messages, v.ErrorMessage)
+		}
+		return strings.Join(messages, "; ")
+	}
+
+	message := strings.TrimSpace(string(body))
+	if len(message) > 200 {
+		message = message[:200]
+	}
+	return message
+}
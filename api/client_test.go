@@ -0,0 +1,173 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestClient starts a server answering with handler and returns a client for it retrying GET requests twice
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(server.URL+"/api/v3", "key", time.Second, RetryPolicy{Retries: 2})
+}
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		status  int
+		body    string
+		want    error // nil when no typed error applies
+		message string
+	}{
+		{http.StatusUnauthorized, "", ErrUnauthorized, "received status code 401"},
+		{http.StatusForbidden, "", ErrUnauthorized, "received status code 403"},
+		{http.StatusNotFound, `{"message": "NotFound"}`, ErrNotFound, "received status code 404: NotFound"},
+		{http.StatusInternalServerError, "database is locked", ErrServer, "received status code 500: database is locked"},
+		{http.StatusServiceUnavailable, "", ErrServer, "received status code 503"},
+		{http.StatusBadRequest, `[{"propertyName": "Path", "errorMessage": "Path is already configured"}]`, nil,
+			"received status code 400: Path is already configured"},
+	}
+
+	for _, test := range tests {
+		t.Run(http.StatusText(test.status), func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			})
+			client.Retry = RetryPolicy{}
+
+			err := client.Get("/series", nil, nil)
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != test.status {
+				t.Fatalf("Get() error = %v, want a StatusError with status %d", err, test.status)
+			}
+			for _, typed := range []error{ErrUnauthorized, ErrNotFound, ErrServer} {
+				if got := errors.Is(err, typed); got != (typed == test.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, typed, got)
+				}
+			}
+			if !strings.HasSuffix(err.Error(), test.message) {
+				t.Errorf("error = %q, want it to end with %q", err, test.message)
+			}
+		})
+	}
+}
+
+func TestRequest(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			t.Errorf("X-Api-Key = %q, want key", r.Header.Get("X-Api-Key"))
+		}
+		if r.URL.Path != "/api/v3/series/lookup" || r.URL.Query().Get("term") != "severance" {
+			t.Errorf("request = %s, want the lookup of severance", r.URL)
+		}
+		w.Write([]byte(`[{"title": "Severance"}]`))
+	})
+
+	var series []struct {
+		Title string `json:"title"`
+	}
+	if err := client.Get("/series/lookup", url.Values{"term": {"severance"}}, &series); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(series) != 1 || series[0].Title != "Severance" {
+		t.Errorf("Get() decoded %+v, want Severance", series)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		status    int
+		wantCalls int
+	}{
+		{"GET retried on server errors", http.MethodGet, http.StatusServiceUnavailable, 3},
+		{"GET not retried on client errors", http.MethodGet, http.StatusNotFound, 1},
+		{"POST not retried", http.MethodPost, http.StatusServiceUnavailable, 1},
+		{"PUT not retried", http.MethodPut, http.StatusServiceUnavailable, 1},
+		{"DELETE not retried", http.MethodDelete, http.StatusServiceUnavailable, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if r.Method != test.method {
+					t.Errorf("method = %s, want %s", r.Method, test.method)
+				}
+				w.WriteHeader(test.status)
+			})
+
+			if err := client.Do(test.method, "/command", nil, nil, nil); err == nil {
+				t.Fatal("Do() error = nil, want the status error")
+			}
+			if calls != test.wantCalls {
+				t.Errorf("%d requests, want %d", calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	calls := 0
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"version": "4.0.0"}`))
+	})
+
+	var status struct {
+		Version string `json:"version"`
+	}
+	if err := client.Get("/system/status", nil, &status); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if status.Version != "4.0.0" || calls != 2 {
+		t.Errorf("Get() = %+v after %d requests, want the second answer", status, calls)
+	}
+}
+
+func TestRetryNetworkErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := NewClient(server.URL, "key", time.Second, RetryPolicy{Retries: 1})
+	err := client.Get("/series", nil, nil)
+	if err == nil || !retryable(err) {
+		t.Errorf("Get() on a closed server error = %v, want a retryable network error", err)
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"message", `{"message": "Series was not found"}`, "Series was not found"},
+		{"validation", `[
+			{"propertyName": "RootFolderPath", "errorMessage": "Folder is not writable"},
+			{"propertyName": "Path", "errorMessage": "Path is already configured"}
+		]`, "Folder is not writable; Path is already configured"},
+		{"plain text", "  Bad Gateway\n", "Bad Gateway"},
+		{"empty", "", ""},
+		{"long", strings.Repeat("x", 300), strings.Repeat("x", 200)},
+		{"empty message", `{"message": ""}`, `{"message": ""}`},
+	}
+
+	for _, test := range tests {
+		if got := errorMessage([]byte(test.body)); got != test.want {
+			t.Errorf("errorMessage(%s) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -11,20 +11,118 @@ import (
 type Auth struct {
 	BotToken            string `json:"bot_token"`
 	SonarrApiToken      string `json:"sonarr_api_token"`
+	RadarrApiToken      string `json:"radarr_api_token"`
+	ProwlarrApiToken    string `json:"prowlarr_api_token"`
+	QBittorrentPassword string `json:"qbittorrent_password"`
+	SabnzbdApiKey       string `json:"sabnzbd_api_key"`
 	Opnsense_api_key    string `json:"opnsense_api_key"`
 	Opnsense_api_secret string `json:"opnsense_api_secret"`
+	WebhookSecret       string `json:"webhook_secret"`
+
+	SonarrApiTokens map[string]string `json:"sonarr_api_tokens"` // keyed by the name of a sonarr_instances entry
 }
 
 type Config struct {
-	SonarrInstance string `json:"sonarr_instance"`
-	SonarrPort     string `json:"sonarr_port"`
-	DbServer       string `json:"db_server"`
-	DbPort         string `json:"db_port"`
-	DbUser         string `json:"db_user"`
-	DbPassword     string `json:"db_user_pass"`
-	DbName         string `json:"db_name"`
-	OpnsenseWanInt string `json:"opnsense_wan_int"`
-	OpnsenseFwIp   string `json:"opnsense_fw_ip"`
+	SonarrInstance         string `json:"sonarr_instance"`
+	SonarrPort             string `json:"sonarr_port"`
+	SonarrTimeoutSeconds   int    `json:"sonarr_timeout_seconds"`
+	SonarrRetries          int    `json:"sonarr_retries"`
+	SonarrCacheMinutes     int    `json:"sonarr_cache_minutes"`
+	SonarrDefaultInstance  string `json:"sonarr_default_instance"`
+	RadarrInstance         string `json:"radarr_instance"`
+	RadarrPort             string `json:"radarr_port"`
+	RadarrTimeoutSeconds   int    `json:"radarr_timeout_seconds"`
+	RadarrRetries          int    `json:"radarr_retries"`
+	RadarrCacheMinutes     int    `json:"radarr_cache_minutes"`
+	ProwlarrInstance       string `json:"prowlarr_instance"`
+	ProwlarrPort           string `json:"prowlarr_port"`
+	ProwlarrTimeoutSeconds int    `json:"prowlarr_timeout_seconds"`
+	ProwlarrRetries        int    `json:"prowlarr_retries"`
+	DbServer               string `json:"db_server"`
+	DbPort                 string `json:"db_port"`
+	DbUser                 string `json:"db_user"`
+	DbPassword             string `json:"db_user_pass"`
+	DbName                 string `json:"db_name"`
+	OpnsenseWanInt         string `json:"opnsense_wan_int"`
+	OpnsenseFwIp           string `json:"opnsense_fw_ip"`
+	DiscordGuildID         string `json:"discord_guild_id"`
+	CommandPrefix          string `json:"command_prefix"`
+	Timezone               string `json:"timezone"` // IANA name, e.g. Australia/Sydney
+
+	Permissions     PermissionConfig       `json:"permissions"`
+	RateLimits      RateLimitConfig        `json:"rate_limits"`
+	Webhook         WebhookConfig          `json:"webhook"`
+	SonarrStatus    StatusMonitorConfig    `json:"sonarr_status"`
+	SonarrInstances []SonarrInstanceConfig `json:"sonarr_instances"`
+	Downloaders     DownloaderConfig       `json:"downloaders"`
+}
+
+// PermissionRule lists the Discord role, user and channel IDs a permission rule applies to
+type PermissionRule struct {
+	Roles    []string `json:"roles"`
+	Users    []string `json:"users"`
+	Channels []string `json:"channels"`
+}
+
+// PermissionConfig maps Discord IDs to the bot permission levels.
+// Commands overrides the level required by a command, e.g. {"wip": "trusted"}.
+type PermissionConfig struct {
+	Trusted  PermissionRule    `json:"trusted"`
+	Admin    PermissionRule    `json:"admin"`
+	Deny     PermissionRule    `json:"deny"`
+	Commands map[string]string `json:"commands"`
+}
+
+// WebhookConfig sets where the webhook listener runs and which channel each event type is posted to.
+// Channels is keyed by event type (grab, download, upgrade, rename, seriesdelete, health, test).
+// RadarrChannels overrides Channels for the Radarr events (grab, download, upgrade, rename, moviedelete...).
+type WebhookConfig struct {
+	Listen         string            `json:"listen"` // e.g. ":8090", the listener is disabled when empty
+	DefaultChannel string            `json:"default_channel"`
+	Channels       map[string]string `json:"channels"`
+	RadarrChannels map[string]string `json:"radarr_channels"`
+}
+
+// StatusMonitorConfig sets the free space warning threshold of !sonarrstatus and the periodic health check.
+// The check runs every IntervalMinutes and posts to Channel, it is disabled when either is not set.
+type StatusMonitorConfig struct {
+	Channel         string  `json:"channel"`
+	IntervalMinutes int     `json:"interval_minutes"`
+	MinFreeGB       float64 `json:"min_free_gb"`
+}
+
+// SonarrInstanceConfig is a named Sonarr instance, in addition to the one set by sonarr_instance.
+// Its API key is read from sonarr_api_tokens in the credentials file under the same name.
+type SonarrInstanceConfig struct {
+	Name           string `json:"name"`
+	Instance       string `json:"instance"`
+	Port           string `json:"port"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+	Retries        int    `json:"retries"`
+}
+
+// DownloaderConfig sets the download clients !dl reports on and controls, a client is left out when its URL
+// is not set. The qBittorrent password and SABnzbd API key are read from the credentials file.
+type DownloaderConfig struct {
+	QBittorrentURL   string `json:"qbittorrent_url"` // e.g. http://10.23.0.3:8080
+	QBittorrentUser  string `json:"qbittorrent_user"`
+	SabnzbdURL       string `json:"sabnzbd_url"`        // e.g. http://10.23.0.3:8085
+	SabnzbdSlowLimit string `json:"sabnzbd_slow_limit"` // "!dl slow on" limit, a percentage or e.g. 2M
+	TimeoutSeconds   int    `json:"timeout_seconds"`
+}
+
+// RateLimitRule is a token bucket holding Burst tokens, refilled by one token every RefillSeconds
+type RateLimitRule struct {
+	Burst         int     `json:"burst"`
+	RefillSeconds float64 `json:"refill_seconds"`
+}
+
+// RateLimitConfig sets the per-user command limits and how many commands may run at the same time.
+// Commands overrides the limit of a single command.
+type RateLimitConfig struct {
+	Default       RateLimitRule            `json:"default"`
+	Commands      map[string]RateLimitRule `json:"commands"`
+	MaxConcurrent int                      `json:"max_concurrent"`
 }
 
 // LoadCreds loads the credentials from the .discordrc file.
@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"main/api"
+	"main/format"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// queuePageSize is the number of queue items shown by !sonarrqueue and !radarrqueue
+const queuePageSize = 50
+
+// arrApp names a Sonarr-like application in the commands the applications share
+type arrApp struct {
+	name   string // shown in messages, e.g. Sonarr
+	prefix string // start of the command names, e.g. sonarr for !sonarrqueue
+}
+
+var (
+	sonarrApp = arrApp{name: "Sonarr", prefix: "sonarr"}
+	radarrApp = arrApp{name: "Radarr", prefix: "radarr"}
+)
+
+// arrErrorMessage logs an API error of app, e.g. Sonarr, and returns the message shown in Discord
+func arrErrorMessage(app, action string, err error) string {
+	log.Printf("Error %s: %v", action, err)
+
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		return fmt.Sprintf("Error %s: %s rejected the API key.", action, app)
+	case errors.Is(err, api.ErrNotFound):
+		return fmt.Sprintf("Error %s: not found on %s.", action, app)
+	case errors.Is(err, api.ErrServer):
+		return fmt.Sprintf("Error %s: %s returned a server error, try again later.", action, app)
+	default:
+		return fmt.Sprintf("Error %s: %s", action, err)
+	}
+}
+
+// arrQueueItem is a download queue item as listed by !sonarrqueue and !radarrqueue
+type arrQueueItem struct {
+	ID                    int
+	Title                 string // the series and episode or the movie, the release title when unknown
+	Progress              float64
+	Size                  int64
+	TimeLeft              string // empty when unknown
+	DownloadClient        string
+	Status                string
+	TrackedDownloadStatus string
+	ErrorMessage          string
+	Warnings              []string
+}
+
+// arrQueue is the download queue of Sonarr or Radarr
+type arrQueue interface {
+	// Items returns the first count items of the queue and the total number of items
+	Items(count int) (items []arrQueueItem, total int, err error)
+	// Remove removes an item from the queue and the download client, optionally blocklisting the release
+	Remove(id int, blocklist bool) error
+}
+
+// handleArrQueue responds to !sonarrqueue and !radarrqueue, listing the queue or removing an item from it
+func handleArrQueue(s *discordgo.Session, m *discordgo.MessageCreate, app arrApp, queue arrQueue, args []string) {
+	log.Printf("%s queue arguments: %v", app.name, args)
+
+	if len(args) == 0 || args[0] == "list" {
+		listArrQueue(s, m, app, queue)
+		return
+	}
+
+	if args[0] == "remove" && len(args) >= 2 {
+		removeArrQueueItem(s, m, app, queue, args[1:])
+		return
+	}
+
+	sendUsage(s, m.ChannelID, app.prefix+"queue")
+}
+
+// listArrQueue sends the download queue, one entry per item
+func listArrQueue(s *discordgo.Session, m *discordgo.MessageCreate, app arrApp, queue arrQueue) {
+	items, total, err := queue.Items(queuePageSize)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage(app.name, "fetching the queue from "+app.name, err))
+		return
+	}
+
+	if len(items) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "The download queue is empty.")
+		return
+	}
+
+	var message string
+	for _, item := range items {
+		eta := item.TimeLeft
+		if eta == "" {
+			eta = "unknown"
+		}
+
+		message += fmt.Sprintf("**[%d] %s**\n- %.1f%% of %s · ETA %s · %s\n- Status: %s",
+			item.ID, item.Title, item.Progress, format.Bytes(item.Size), eta, item.DownloadClient, item.Status)
+
+		if item.TrackedDownloadStatus != "" && item.TrackedDownloadStatus != "ok" {
+			message += fmt.Sprintf(" ⚠️ %s", item.TrackedDownloadStatus)
+		}
+		message += "\n"
+
+		if item.ErrorMessage != "" {
+			message += fmt.Sprintf("- Error: %s\n", item.ErrorMessage)
+		}
+		for _, warning := range item.Warnings {
+			message += fmt.Sprintf("- Warning: %s\n", warning)
+		}
+		message += "\n"
+	}
+
+	if total > len(items) {
+		message += fmt.Sprintf("Showing %d of %d items.\n", len(items), total)
+	}
+	message += fmt.Sprintf("Remove a stuck item with `%s%squeue remove <id> [blocklist]`.", Commands.Prefix, app.prefix)
+
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// removeArrQueueItem removes an item from the queue and the download client, blocklisting it when asked
+func removeArrQueueItem(s *discordgo.Session, m *discordgo.MessageCreate, app arrApp, queue arrQueue, args []string) {
+	if !requirePermission(s, m, PermissionTrusted, "remove items from the queue") {
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		sendUsage(s, m.ChannelID, app.prefix+"queue")
+		return
+	}
+
+	// "blocklist" from the prefix command, "true" from the slash command boolean option
+	blocklist := len(args) > 1 && (strings.EqualFold(args[1], "blocklist") || args[1] == "true")
+
+	if err := queue.Remove(id, blocklist); err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage(app.name, fmt.Sprintf("removing queue item %d", id), err))
+		return
+	}
+
+	log.Printf("%s queue item %d removed by %s (blocklist: %v)", app.name, id, m.Author.Username, blocklist)
+	message := fmt.Sprintf("🗑️ Removed queue item %d from %s and the download client.", id, app.name)
+	if blocklist {
+		message += " The release was added to the blocklist."
+	}
+	s.ChannelMessageSend(m.ChannelID, message)
+}
@@ -0,0 +1,305 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/format"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// arrAddKind holds what sets the !sonarradd and !radarradd menus apart besides the adder
+type arrAddKind struct {
+	app           arrApp
+	title         string   // placeholder of the first menu, e.g. Movie
+	singular      string   // e.g. movie
+	plural        string   // e.g. movies
+	optionLabel   string   // label of the last menu, e.g. Monitor
+	options       []string // values of the last menu
+	defaultOption string
+	searchLabel   string // label of the button adding and searching
+}
+
+// arrAddResult is a lookup result offered in the first menu
+type arrAddResult struct {
+	Title       string
+	Year        int
+	Description string // shown under the title, e.g. the network and the TVDB ID
+}
+
+// arrProfile is a quality profile offered in the profile menu
+type arrProfile struct {
+	ID   int
+	Name string
+}
+
+// arrRootFolder is a root folder offered in the folder menu
+type arrRootFolder struct {
+	Path      string
+	FreeSpace int64
+}
+
+// arrAddChoices are the options picked in the menus
+type arrAddChoices struct {
+	Result    int // index into the lookup results, -1 until chosen
+	ProfileID int
+	RootPath  string
+	Option    string // value of the last menu
+}
+
+// arrAdder is the application side of the add menus, implemented for Sonarr and Radarr. An adder is created
+// for each command and keeps the lookup results it returned so Add can post the chosen one.
+type arrAdder interface {
+	// Lookup looks term up and returns at most limit results not added yet, and how many are already added
+	Lookup(term string, limit int) (results []arrAddResult, alreadyAdded int, err error)
+	// QualityProfiles returns the quality profiles the new item can be assigned
+	QualityProfiles() ([]arrProfile, error)
+	// RootFolders returns the folders the new item can be stored in
+	RootFolders() ([]arrRootFolder, error)
+	// Add adds the chosen lookup result, searching for it when asked, and returns the message shown once added
+	Add(choices arrAddChoices, search bool) (string, error)
+}
+
+// arrAddRequest is the state of a !sonarradd or !radarradd message while the user picks the options.
+// Only choices and done change once the message is sent, under mu as clicks are handled concurrently.
+type arrAddRequest struct {
+	kind        *arrAddKind
+	adder       arrAdder
+	results     []arrAddResult // at most 25, the size of a select menu
+	profiles    []arrProfile
+	rootFolders []arrRootFolder
+
+	mu      sync.Mutex
+	choices arrAddChoices
+	done    bool // added or cancelled, the choices no longer change
+}
+
+// arrAddRequests holds the pending !sonarradd and !radarradd messages by message ID
+var arrAddRequests = newSessionStore[*arrAddRequest](15 * time.Minute)
+
+// handleArrAdd responds to !sonarradd and !radarradd with menus to pick what to add and its options
+func handleArrAdd(s *discordgo.Session, m *discordgo.MessageCreate, kind *arrAddKind, adder arrAdder, args []string) {
+	app := kind.app.name
+	log.Printf("%s add arguments: %v", app, args)
+
+	// Check if argument is provided
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, kind.app.prefix+"add")
+		return
+	}
+
+	// Only offer what is not added yet, a select menu holds at most 25 options
+	results, alreadyAdded, err := adder.Lookup(strings.Join(args, " "), 25)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage(app, fmt.Sprintf("looking up %s on %s", kind.plural, app), err))
+		return
+	}
+
+	if len(results) == 0 {
+		if alreadyAdded > 0 {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Every %s found is already in %s.", kind.singular, app))
+		} else {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("No %s found.", kind.plural))
+		}
+		return
+	}
+
+	request := &arrAddRequest{
+		kind:    kind,
+		adder:   adder,
+		results: results,
+		choices: arrAddChoices{Result: -1, Option: kind.defaultOption},
+	}
+
+	// Fetch the values the user picks from
+	request.profiles, err = adder.QualityProfiles()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage(app, "fetching quality profiles from "+app, err))
+		return
+	}
+
+	request.rootFolders, err = adder.RootFolders()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage(app, "fetching root folders from "+app, err))
+		return
+	}
+
+	if len(request.profiles) == 0 || len(request.rootFolders) == 0 {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("%s has no quality profile or root folder set up, add one in %s first.", app, app))
+		return
+	}
+
+	// Preselect the options when there is only one choice
+	if len(request.results) == 1 {
+		request.choices.Result = 0
+	}
+	if len(request.profiles) == 1 {
+		request.choices.ProfileID = request.profiles[0].ID
+	}
+	if len(request.rootFolders) == 1 {
+		request.choices.RootPath = request.rootFolders[0].Path
+	}
+
+	message, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content:    request.content(),
+		Components: request.components(),
+	})
+	if err != nil {
+		log.Printf("Error sending %s add message: %v", app, err)
+		return
+	}
+
+	arrAddRequests.put(message.ID, m.Author.ID, request)
+}
+
+// handleArrAddComponent handles the menus and buttons of a !sonarradd or !radarradd message
+func handleArrAddComponent(s *discordgo.Session, i *discordgo.InteractionCreate, action string, values []string) {
+	request, ok := arrAddRequests.get(s, i)
+	if !ok {
+		return
+	}
+
+	if search, add := request.update(s, i, action, values); add {
+		request.add(s, i, search)
+	}
+}
+
+// update applies a menu choice or button click and updates the message, holding the lock so clicks apply one
+// at a time. add is true when an add button was clicked, the request is then done and add must be called.
+func (r *arrAddRequest) update(s *discordgo.Session, i *discordgo.InteractionCreate, action string, values []string) (search, add bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another click added or cancelled the request while this one waited
+	if r.done {
+		respondEphemeral(s, i, fmt.Sprintf("This %s is already added or cancelled.", r.kind.singular))
+		return false, false
+	}
+
+	switch action {
+	case "result":
+		r.choices.Result, _ = strconv.Atoi(values[0])
+	case "profile":
+		r.choices.ProfileID, _ = strconv.Atoi(values[0])
+	case "root":
+		index, _ := strconv.Atoi(values[0])
+		r.choices.RootPath = r.rootFolders[index].Path
+	case "option":
+		r.choices.Option = values[0]
+	case "cancel":
+		r.done = true
+		arrAddRequests.remove(i.Message.ID)
+		respondUpdate(s, i, fmt.Sprintf("Add %s cancelled.", r.kind.singular), nil)
+		return false, false
+	case "add", "addsearch":
+		r.done = true
+		arrAddRequests.remove(i.Message.ID)
+		return action == "addsearch", true
+	}
+
+	respondUpdate(s, i, r.content(), r.components())
+	return false, false
+}
+
+// add adds the chosen result and reports the outcome on the message, once update has marked the request done
+func (r *arrAddRequest) add(s *discordgo.Session, i *discordgo.InteractionCreate, search bool) {
+	app := r.kind.app.name
+	result := r.results[r.choices.Result]
+
+	// Acknowledge straight away, adding can take longer than Discord waits for an answer
+	respondUpdate(s, i, fmt.Sprintf("Adding **%s** to %s...", result.Title, app), nil)
+
+	content, err := r.adder.Add(r.choices, search)
+	if err != nil {
+		content = arrErrorMessage(app, fmt.Sprintf("adding %s to %s", result.Title, app), err)
+	}
+
+	_, err = s.ChannelMessageEdit(i.ChannelID, i.Message.ID, content)
+	if err != nil {
+		log.Printf("Error editing %s add message: %v", app, err)
+	}
+}
+
+// content describes the current choices of the request
+func (r *arrAddRequest) content() string {
+	if r.choices.Result < 0 {
+		return fmt.Sprintf("Found %d %s, pick the one to add and its options:", len(r.results), r.kind.plural)
+	}
+	result := r.results[r.choices.Result]
+	return fmt.Sprintf("Add **%s** (%d) to %s, pick the options:", result.Title, result.Year, r.kind.app.name)
+}
+
+// components renders the menus with the current choices selected, and the buttons
+func (r *arrAddRequest) components() []discordgo.MessageComponent {
+	var resultOptions []discordgo.SelectMenuOption
+	for index, result := range r.results {
+		resultOptions = append(resultOptions, discordgo.SelectMenuOption{
+			Label:       format.Truncate(fmt.Sprintf("%s (%d)", result.Title, result.Year), 100),
+			Value:       strconv.Itoa(index),
+			Description: format.Truncate(result.Description, 100),
+			Default:     index == r.choices.Result,
+		})
+	}
+
+	var profileOptions []discordgo.SelectMenuOption
+	for _, profile := range r.profiles {
+		profileOptions = append(profileOptions, discordgo.SelectMenuOption{
+			Label:   format.Truncate(profile.Name, 100),
+			Value:   strconv.Itoa(profile.ID),
+			Default: profile.ID == r.choices.ProfileID,
+		})
+		if len(profileOptions) == 25 {
+			break
+		}
+	}
+
+	var rootOptions []discordgo.SelectMenuOption
+	for index, folder := range r.rootFolders {
+		rootOptions = append(rootOptions, discordgo.SelectMenuOption{
+			Label:       format.Truncate(folder.Path, 100),
+			Value:       strconv.Itoa(index),
+			Description: fmt.Sprintf("%s free", format.Bytes(folder.FreeSpace)),
+			Default:     folder.Path == r.choices.RootPath,
+		})
+		if len(rootOptions) == 25 {
+			break
+		}
+	}
+
+	var lastOptions []discordgo.SelectMenuOption
+	for _, value := range r.kind.options {
+		lastOptions = append(lastOptions, discordgo.SelectMenuOption{
+			Label:   r.kind.optionLabel + ": " + value,
+			Value:   value,
+			Default: value == r.choices.Option,
+		})
+	}
+
+	ready := r.choices.Result >= 0 && r.choices.ProfileID != 0 && r.choices.RootPath != ""
+
+	// The custom IDs start with the command name, e.g. sonarradd:profile, see componentHandlers
+	id := r.kind.app.prefix + "add:"
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{CustomID: id + "result", Placeholder: r.kind.title, Options: resultOptions},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{CustomID: id + "profile", Placeholder: "Quality profile", Options: profileOptions},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{CustomID: id + "root", Placeholder: "Root folder", Options: rootOptions},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{CustomID: id + "option", Placeholder: r.kind.optionLabel, Options: lastOptions},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{CustomID: id + "add", Label: "Add", Style: discordgo.SuccessButton, Disabled: !ready},
+			discordgo.Button{CustomID: id + "addsearch", Label: r.kind.searchLabel, Style: discordgo.PrimaryButton, Disabled: !ready},
+			discordgo.Button{CustomID: id + "cancel", Label: "Cancel", Style: discordgo.SecondaryButton},
+		}},
+	}
+}
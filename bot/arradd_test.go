@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeAdder is an arrAdder recording the choices it is asked to add
+type fakeAdder struct {
+	mu    sync.Mutex
+	added []arrAddChoices
+}
+
+// Lookup is not used once the menus are sent
+func (f *fakeAdder) Lookup(term string, limit int) ([]arrAddResult, int, error) {
+	return nil, 0, nil
+}
+
+// QualityProfiles is not used once the menus are sent
+func (f *fakeAdder) QualityProfiles() ([]arrProfile, error) {
+	return nil, nil
+}
+
+// RootFolders is not used once the menus are sent
+func (f *fakeAdder) RootFolders() ([]arrRootFolder, error) {
+	return nil, nil
+}
+
+// Add records the choices
+func (f *fakeAdder) Add(choices arrAddChoices, search bool) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.added = append(f.added, choices)
+	return "added", nil
+}
+
+// TestArrAddConcurrentClicks clicks the menus and the add buttons of the same message at once, run it with
+// -race to check the choices are not changed while they are read
+func TestArrAddConcurrentClicks(t *testing.T) {
+	s, _ := newTestSession(t)
+	adder := &fakeAdder{}
+	request := &arrAddRequest{
+		kind:        sonarrAddKind,
+		adder:       adder,
+		results:     []arrAddResult{{Title: "Severance", Year: 2022}},
+		profiles:    []arrProfile{{ID: 1, Name: "HD"}, {ID: 2, Name: "4K"}},
+		rootFolders: []arrRootFolder{{Path: "/tv"}},
+		choices:     arrAddChoices{Result: 0, ProfileID: 1, RootPath: "/tv", Option: "all"},
+	}
+	arrAddRequests.put("message", "user", request)
+	t.Cleanup(func() { arrAddRequests.remove("message") })
+
+	// Each click has its own interaction ID so discordgo does not queue the responses in the same rate limit
+	// bucket, which would order the clicks
+	click := func(n int, action string, values []string) {
+		i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+			ID:        "interaction" + strconv.Itoa(n),
+			ChannelID: "channel",
+			Message:   &discordgo.Message{ID: "message", ChannelID: "channel"},
+			User:      &discordgo.User{ID: "user", Username: "tester"},
+		}}
+		handleArrAddComponent(s, i, action, values)
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < 20; n++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			click(2*n, "profile", []string{strconv.Itoa(n%2 + 1)})
+		}()
+		go func() {
+			defer wg.Done()
+			click(2*n+1, "add", nil)
+		}()
+	}
+	wg.Wait()
+
+	if len(adder.added) != 1 {
+		t.Fatalf("Add called %d times, want once", len(adder.added))
+	}
+	if profile := adder.added[0].ProfileID; profile != 1 && profile != 2 {
+		t.Errorf("added with profile %d, want one of the menu values", profile)
+	}
+}
@@ -1,15 +1,17 @@
 package bot
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"main/api"
 	"main/auth"
 	"main/postgres"
+	"math"
 	"os"
 	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	//"honnef.co/go/tools/config"
@@ -18,23 +20,19 @@ import (
 // CommandHandler defines the function signature for a command
 type CommandHandler func(s *discordgo.Session, m *discordgo.MessageCreate, args []string)
 
-// CommandHandlers holds the mapping of commands to their handlers
-var CommandHandlers map[string]CommandHandler
+// Commands routes messages to the registered commands
+var Commands *Router
 
-// Declare the variable at the package level
-var sonarrLocalSearchUrl string
+// botLocation is the timezone dates are shown in, set from the config file
+var botLocation = time.Local
 
 func Init() {
-	// Initialize the CommandHandlers map
-	CommandHandlers = map[string]CommandHandler{
-		"!help":         handleHelp,
-		"!bye":          handleBye,
-		"!echo":         handleEcho,
-		"!sonarrlookup": handleSonarrSeriesLookup,
-		"!sonarrls":     handleSonarrLocalSeriesSearch, // search only the local sonarr instance
-		"!dbver":        handleDatabaseVersion,
-		"!add":          handleDbInsertMangaName,
-		"!wip":          handleCurrentWanIP, // get current WAN IP from FW
+	// Register the commands, the prefix is replaced below if one is configured
+	Commands = NewRouter("!")
+	for _, cmd := range commandList() {
+		if err := Commands.Register(cmd); err != nil {
+			log.Fatalf("Error registering command: %v", err)
+		}
 	}
 
 	// Load the local config file
@@ -44,8 +42,37 @@ func Init() {
 		return
 	}
 
-	sonarrLocalSearchUrl = api.ConstructSonarrLocalSeriesURL(config.SonarrInstance, config.SonarrPort)
+	if config.CommandPrefix != "" {
+		Commands.Prefix = config.CommandPrefix
+	}
+
+	permissionConfig = config.Permissions
+	commandLimiter = NewRateLimiter(config.RateLimits)
+	statusConfig = config.SonarrStatus
+
+	if config.Timezone != "" {
+		location, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			log.Println("Error loading timezone, using the system timezone:", err)
+		} else {
+			botLocation = location
+		}
+	}
+
+	// Load the credentials for the API clients
+	creds, err := auth.LoadCreds()
+	if err != nil {
+		log.Println("Error loading credentials:", err)
+		return
+	}
+
+	// Keep each series list in memory so searches and autocomplete do not fetch it from Sonarr every time
+	loadSonarrInstances(config, creds)
+	addSonarrInstanceOption()
 
+	loadRadarr(config, creds)
+	loadProwlarr(config, creds)
+	loadDownloaders(config, creds)
 }
 
 func RunBot() {
@@ -60,11 +87,29 @@ func RunBot() {
 
 	// add a event handler
 	discordBot.AddHandler(messageHandler)
+	discordBot.AddHandler(interactionHandler) // slash commands and autocomplete
 
 	// open session
 	discordBot.Open()
 	defer discordBot.Close() // close session, after function termination
 
+	// register the slash commands, the session must be open so the application ID is known
+	registerSlashCommands(discordBot)
+
+	// keep the Sonarr series and Radarr movie caches fresh in the background
+	for _, instance := range sonarrInstances {
+		go instance.client.Run()
+	}
+	if radarrClient != nil {
+		go radarrClient.Run()
+	}
+
+	// start receiving the Sonarr and Radarr webhook events
+	startWebhookServer(discordBot)
+
+	// start the periodic Sonarr health and disk space check
+	startSonarrStatusMonitor(discordBot)
+
 	// exectuion until os signal interruption (ctrl + C)
 	log.Println("nnDiscordBot started....")
 	botChannel := make(chan os.Signal, 1)
@@ -81,215 +126,108 @@ func messageHandler(s *discordgo.Session, m *discordgo.MessageCreate) {
 		return
 	}
 
-	// Check if the message starts with a recognized command
-	for cmd, handler := range CommandHandlers {
-		if strings.HasPrefix(m.Content, cmd) {
-			// Split the message to get the command arguments
-			args := strings.Fields(m.Content[len(cmd):])
-			handler(s, m, args)
-			return
-		}
-	}
-}
-
-// handleHelp responds to the !help command
-func handleHelp(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	response := "Hello! Here are the available commands:\n"
-	for cmd := range CommandHandlers {
-		response += fmt.Sprintf("- %s\n", cmd)
-	}
-	s.ChannelMessageSend(m.ChannelID, response)
-}
-
-// handleBye responds to the !bye command
-func handleBye(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	s.ChannelMessageSend(m.ChannelID, "Goodbye! 👋")
-}
-
-// handleEcho responds to the !echo command and demonstrates argument usage
-func handleEcho(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: !echo <message>")
-		return
-	}
-	// Join the arguments into a single string
-	message := strings.Join(args, " ")
-	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("You said: %s", message))
-}
-
-// handleSonarr responds to the !sonarrlookup command and demonstrates argument usage
-func handleSonarrSeriesLookup(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	// Load the credentials from the auth package
-	creds, err := auth.LoadCreds()
-	if err != nil {
-		log.Println("Error loading credentials:", err)
-		s.ChannelMessageSend(m.ChannelID, "Error loading credentials. Please try again later.")
-		return
-	}
-	sonarrApiKey := creds.SonarrApiToken
-
-	log.Println("Sonarr Lookup arguments:", args)
-
-	// Check if argument is provided
-	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: !sonarrlookup <series_name>")
+	// Split the message into the command name and its arguments
+	name, args, ok := Commands.Parse(m.Content)
+	if !ok {
 		return
 	}
 
-	// Call the Sonarr API
-	url := sonarrLocalSearchUrl + "/lookup" // search url var declared in the init function
-
-	result, err := api.SonarrSeriesLookupAPICall(url, "X-Api-Key", sonarrApiKey, "term", args[0])
-	if err != nil {
-		log.Println("Error handling Sonarr API call:", err)
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Error handling Sonarr API call: %s", err))
+	cmd, ok := Commands.Lookup(name)
+	if !ok {
+		log.Printf("Unknown command %q from %s", name, m.Author.Username)
+		response := fmt.Sprintf("Unknown command `%s%s`.", Commands.Prefix, name)
+		if suggestions := Commands.Suggest(name); len(suggestions) > 0 {
+			for i, suggestion := range suggestions {
+				suggestions[i] = fmt.Sprintf("`%s%s`", Commands.Prefix, suggestion)
+			}
+			response += fmt.Sprintf(" Did you mean %s?", strings.Join(suggestions, ", "))
+		} else {
+			response += fmt.Sprintf(" Type `%shelp` for the list of commands.", Commands.Prefix)
+		}
+		s.ChannelMessageSend(m.ChannelID, response)
 		return
 	}
 
-	// Process the Sonarr API response to extract series titles
-	seriesTitles, err := api.ProcessSeriesLookupResponse(result)
+	// Check the user is allowed to run the command now before handing over to it
+	release, err := admitCommand(m, cmd)
 	if err != nil {
-		log.Println("Error processing Sonarr API response:", err)
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Error processing Sonarr API response: %s", err))
+		s.ChannelMessageSend(m.ChannelID, err.Error())
 		return
 	}
+	defer release()
 
-	log.Println("Series titles:", seriesTitles)
+	cmd.Handler(s, m, args)
+}
 
-	// Prepare the response message with series titles
-	if len(seriesTitles) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "No series found.")
-		return
+// admitCommand runs the permission and rate limit checks for cmd and reserves a concurrent command slot.
+// The returned release function must be called once the command has finished.
+func admitCommand(m *discordgo.MessageCreate, cmd *Command) (func(), error) {
+	if err := authorize(m, cmd); err != nil {
+		return nil, err
 	}
 
-	var message string
-	for _, title := range seriesTitles {
-		message += fmt.Sprintf("- %s\n", title)
+	// Take the slot first so a user turned away because the bot is busy keeps their token for the retry
+	if !commandLimiter.Acquire() {
+		log.Printf("Busy: rejected %s from %s (%s)", cmd.Name, m.Author.Username, m.Author.ID)
+		return nil, errors.New("⏳ I'm busy with other commands, try again in a few seconds.")
 	}
 
-	// Function to split message into chunks of 2000 characters or less
-	sendMessageChunks := func(message string) {
-		for len(message) > 2000 {
-			// Find the last line break within 2000 characters
-			truncatedMessage := message[:2000]
-			lastNewlineIndex := strings.LastIndex(truncatedMessage, "\n")
-
-			if lastNewlineIndex == -1 {
-				// If there's no newline in the first 2000 characters, send the whole chunk
-				s.ChannelMessageSend(m.ChannelID, truncatedMessage)
-				message = message[2000:]
-			} else {
-				// Send the chunk up to the last complete series
-				s.ChannelMessageSend(m.ChannelID, message[:lastNewlineIndex+1])
-				message = message[lastNewlineIndex+1:]
-			}
-		}
-
-		// Send the remaining message (less than 2000 characters)
-		if len(message) > 0 {
-			s.ChannelMessageSend(m.ChannelID, message)
-		}
+	if ok, retryAfter := commandLimiter.Allow(m.Author.ID, cmd); !ok {
+		commandLimiter.Release()
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		log.Printf("Rate limited: %s (%s) running %s, retry in %ds", m.Author.Username, m.Author.ID, cmd.Name, seconds)
+		return nil, fmt.Errorf("⏳ Slow down! You can use `%s%s` again in %ds.", Commands.Prefix, cmd.Name, seconds)
 	}
 
-	// Send the message in chunks if it's too long
-	sendMessageChunks(message)
+	return commandLimiter.Release, nil
 }
 
-// handleSonarr responds to the !sonarrls command
-func handleSonarrLocalSeriesSearch(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	// Load the credentials from the auth package
-	creds, err := auth.LoadCreds()
-	if err != nil {
-		log.Println("Error loading credentials:", err)
-		s.ChannelMessageSend(m.ChannelID, "Error loading credentials. Please try again later.")
-		return
-	}
-
-	sonarrApiKey := creds.SonarrApiToken
-	log.Println("Sonarr API Local search arguments:", args)
-
-	// Check if argument is provided
+// handleHelp responds to the !help command, optionally with the details of a single command
+func handleHelp(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
 	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: !sonarrls <series_name>\n(sonarr local search)")
-		return
-	}
-
-	// Fetch all series from the local Sonarr instance
-	allSeriesJSON, err := api.SonarrFetchAllLocalSeries(sonarrLocalSearchUrl, sonarrApiKey)
-	if err != nil {
-		log.Println("Error fetching series from Sonarr:", err)
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Error fetching series from Sonarr: %s", err))
-		return
-	}
-
-	// Unmarshal all series data
-	var allSeries []api.Series
-	if err := json.Unmarshal([]byte(allSeriesJSON), &allSeries); err != nil {
-		log.Println("Error unmarshalling Sonarr API response:", err)
-		s.ChannelMessageSend(m.ChannelID, "Error processing Sonarr API response.")
+		if _, err := s.ChannelMessageSendEmbed(m.ChannelID, helpOverviewEmbed()); err != nil {
+			log.Println("Error sending the help overview:", err)
+		}
 		return
 	}
 
-	// Search for series matches
-	searchQuery := strings.ToLower(strings.Join(args, " "))
-	var matchingSeries []api.Series
-
-	for _, series := range allSeries {
-		title := strings.ToLower(series.Title)
-		if strings.Contains(title, searchQuery) {
-			matchingSeries = append(matchingSeries, series)
+	name := strings.TrimPrefix(args[0], Commands.Prefix)
+	cmd, ok := Commands.Lookup(name)
+	if !ok {
+		response := fmt.Sprintf("Unknown command `%s%s`.", Commands.Prefix, name)
+		if suggestions := Commands.Suggest(name); len(suggestions) > 0 {
+			response += fmt.Sprintf(" Did you mean `%s%s`?", Commands.Prefix, suggestions[0])
 		}
-	}
-
-	// Prepare the response message
-	if len(matchingSeries) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "No series found.")
+		s.ChannelMessageSend(m.ChannelID, response)
 		return
 	}
 
-	// Build the response message
-	var message string
-	for _, series := range matchingSeries {
-		message += fmt.Sprintf(
-			"**%s** (ID: %d)\n- Seasons: %d\n- Episodes: %d\n- Year: %d\n- Status: %s\n- Genres: %s\n\n",
-			series.Title, series.ID, series.SeasonCount, series.EpisodesCount, series.Year,
-			series.Status, strings.Join(series.Genres, ", "),
-		)
+	if _, err := s.ChannelMessageSendEmbed(m.ChannelID, helpCommandEmbed(cmd)); err != nil {
+		log.Println("Error sending the command help:", err)
 	}
+}
 
-	// Function to split and send message chunks
-	sendMessageChunks := func(message string) {
-		for len(message) > 2000 {
-			// Find the last newline before 2000 characters
-			truncatedMessage := message[:2000]
-			lastNewlineIndex := strings.LastIndex(truncatedMessage, "\n")
-
-			if lastNewlineIndex == -1 {
-				// No newline, send the whole chunk
-				s.ChannelMessageSend(m.ChannelID, truncatedMessage)
-				message = message[2000:]
-			} else {
-				// Send up to the last newline
-				s.ChannelMessageSend(m.ChannelID, message[:lastNewlineIndex+1])
-				message = message[lastNewlineIndex+1:]
-			}
-		}
+// handleBye responds to the !bye command
+func handleBye(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	s.ChannelMessageSend(m.ChannelID, "Goodbye! 👋")
+}
 
-		// Send any remaining part of the message
-		if len(message) > 0 {
-			s.ChannelMessageSend(m.ChannelID, message)
-		}
+// handleEcho responds to the !echo command and demonstrates argument usage
+func handleEcho(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "echo")
+		return
 	}
-
-	// Send the response
-	sendMessageChunks(message)
+	// Join the arguments into a single string
+	message := strings.Join(args, " ")
+	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("You said: %s", message))
 }
 
 // database version lookup
 func handleDatabaseVersion(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	// Check if argument is provided
-	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: !dbver - returns the database version)")
+	// Check that no argument is provided
+	if len(args) != 0 {
+		sendUsage(s, m.ChannelID, "dbver")
 		return
 	}
 
@@ -302,7 +240,7 @@ func handleDatabaseVersion(s *discordgo.Session, m *discordgo.MessageCreate, arg
 func handleDbInsertMangaName(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
 	// Check if argument is provided
 	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: !add New manga Name to add to database)")
+		sendUsage(s, m.ChannelID, "add")
 		return
 	}
 
@@ -323,7 +261,7 @@ func handleDbInsertMangaName(s *discordgo.Session, m *discordgo.MessageCreate, a
 func handleCurrentWanIP(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
 	// Check if argument is provided
 	if len(args) != 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: !wip Returns FW WAN int IP address - No args)")
+		sendUsage(s, m.ChannelID, "wip")
 		return
 	}
 
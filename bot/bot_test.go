@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TestMain registers the commands as Init does, without reading the config file, and silences the logs of
+// the handlers
+func TestMain(m *testing.M) {
+	Commands = NewRouter("!")
+	for _, cmd := range commandList() {
+		if err := Commands.Register(cmd); err != nil {
+			log.Fatalf("Error registering command: %v", err)
+		}
+	}
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// roundTripFunc answers the HTTP requests of a Discord session
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// newTestSession returns a Discord session whose REST calls are answered locally, and the content of the
+// messages it sent
+func newTestSession(t *testing.T) (*discordgo.Session, *[]string) {
+	s, err := discordgo.New("Bot test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []string
+	s.Client = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var message discordgo.MessageSend
+		if req.Body != nil {
+			body, _ := io.ReadAll(req.Body)
+			json.Unmarshal(body, &message)
+		}
+		if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/messages") {
+			sent = append(sent, message.Content)
+		}
+
+		reply, _ := json.Marshal(discordgo.Message{ID: "1", Content: message.Content})
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(string(reply))),
+			Request:    req,
+		}, nil
+	})}
+	return s, &sent
+}
+
+// testMessage returns a message sent in channel "channel" by user "tester"
+func testMessage() *discordgo.MessageCreate {
+	return &discordgo.MessageCreate{Message: &discordgo.Message{
+		ChannelID: "channel",
+		Author:    &discordgo.User{ID: "user", Username: "tester"},
+	}}
+}
@@ -0,0 +1,795 @@
+package bot
+
+import (
+	"fmt"
+	"main/auth"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Category groups related commands in the help output
+type Category string
+
+const (
+	CategorySonarr    Category = "Sonarr"
+	CategoryRadarr    Category = "Radarr"
+	CategoryProwlarr  Category = "Prowlarr"
+	CategoryDownloads Category = "Downloads"
+	CategoryFirewall  Category = "Firewall"
+	CategoryDatabase  Category = "Database"
+	CategoryUtility   Category = "Utility"
+)
+
+// categories lists the categories in the order they are shown by !help
+var categories = []Category{CategorySonarr, CategoryRadarr, CategoryProwlarr, CategoryDownloads, CategoryFirewall, CategoryDatabase, CategoryUtility}
+
+// Permission is the level a user needs to run a command
+type Permission int
+
+const (
+	PermissionEveryone Permission = iota
+	PermissionTrusted
+	PermissionAdmin
+)
+
+// String returns the permission level name as used in the help output
+func (p Permission) String() string {
+	switch p {
+	case PermissionTrusted:
+		return "trusted"
+	case PermissionAdmin:
+		return "admin"
+	default:
+		return "everyone"
+	}
+}
+
+// Command describes a bot command, the names it answers to and the metadata shown by !help.
+// Usage and Examples are written without the command prefix and name, e.g. Usage "<series_name>".
+type Command struct {
+	Name         string
+	Aliases      []string
+	Category     Category
+	Usage        string
+	Description  string
+	Examples     []string
+	Permission   Permission
+	RateLimit    auth.RateLimitRule // zero values fall back to the configured default
+	Handler      CommandHandler
+	Options      []*discordgo.ApplicationCommandOption // slash command options
+	Autocomplete AutocompleteHandler                   // choices for options with Autocomplete set
+	Flags        []string                              // slash options passed to the handler as "--name value"
+}
+
+// minCalendarDays is the smallest value of the !sonarrcal and !radarrcal days option, a pointer is needed for MinValue
+var minCalendarDays = 1.0
+
+// commandList returns the commands registered by Init
+func commandList() []*Command {
+	return []*Command{
+		{
+			Name:        "help",
+			Aliases:     []string{"h", "commands"},
+			Category:    CategoryUtility,
+			Usage:       "[command]",
+			Description: "List the available commands, or show the details of one command",
+			Examples:    []string{"help", "help sonarrls"},
+			Handler:     handleHelp,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "command",
+					Description:  "Command to show the details of",
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteCommandName,
+		},
+		{
+			Name:        "bye",
+			Category:    CategoryUtility,
+			Description: "Say goodbye",
+			Examples:    []string{"bye"},
+			Handler:     handleBye,
+		},
+		{
+			Name:        "echo",
+			Category:    CategoryUtility,
+			Usage:       "<message>",
+			Description: "Echo a message back to the channel",
+			Examples:    []string{"echo hello world"},
+			Handler:     handleEcho,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "message",
+					Description: "Message to echo",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "sonarrlookup",
+			Category:    CategorySonarr,
+			Usage:       "<series_name>",
+			Description: "Look up a series by name on Sonarr",
+			Examples:    []string{"sonarrlookup severance"},
+			Handler:     sonarrHandler(handleSonarrSeriesLookup),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "term",
+					Description: "Series name to look up",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "sonarrls",
+			Category:    CategorySonarr,
+			Usage:       "<series_name>",
+			Description: "Search the series already added to the local Sonarr instance",
+			Examples:    []string{"sonarrls the office"},
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // fetches every series from Sonarr when the cache is stale
+			Handler:     handleSonarrLocalSeriesSearch,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Series name (or part of it)",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarradd",
+			Category:    CategorySonarr,
+			Usage:       "<series_name>",
+			Description: "Look up a series and add it to Sonarr, picking the quality profile, root folder and monitoring",
+			Examples:    []string{"sonarradd severance"},
+			Permission:  PermissionTrusted,
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // looks the series up on TheTVDB
+			Handler:     sonarrHandler(handleSonarrAdd),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "term",
+					Description: "Series name to look up",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "sonarrcal",
+			Aliases:     []string{"airing"},
+			Category:    CategorySonarr,
+			Usage:       "[days]",
+			Description: "List the episodes airing in the coming days (7 by default, up to 31)",
+			Examples:    []string{"sonarrcal", "sonarrcal 14"},
+			Handler:     sonarrHandler(handleSonarrCalendar),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Number of days to show",
+					MinValue:    &minCalendarDays,
+					MaxValue:    maxCalendarDays,
+				},
+			},
+		},
+		{
+			Name:        "sonarrqueue",
+			Category:    CategorySonarr,
+			Usage:       "[remove <id> [blocklist]]",
+			Description: "Show the Sonarr download queue, or remove a stuck item (remove needs trusted)",
+			Examples:    []string{"sonarrqueue", "sonarrqueue remove 1234", "sonarrqueue remove 1234 blocklist"},
+			Handler:     sonarrHandler(handleSonarrQueue),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Show the download queue",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Remove an item from the queue and the download client",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "id",
+							Description: "Queue item ID",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "blocklist",
+							Description: "Add the release to the blocklist",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "sonarrmissing",
+			Aliases:     []string{"missing"},
+			Category:    CategorySonarr,
+			Usage:       "[series_name|series_id]",
+			Description: "List the missing monitored episodes, of every series or one, with buttons to search for them",
+			Examples:    []string{"sonarrmissing", "sonarrmissing severance"},
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // fetches the missing episodes, and every series from Sonarr when the cache is stale
+			Handler:     sonarrHandler(handleSonarrMissing),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Only list the missing episodes of this series",
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarrcmd",
+			Category:    CategorySonarr,
+			Usage:       "rescan|refresh|rss|backup|missingsearch [series_name|series_id]",
+			Description: "Run a Sonarr job and follow its progress",
+			Examples:    []string{"sonarrcmd rss", "sonarrcmd refresh severance", "sonarrcmd backup"},
+			Permission:  PermissionTrusted,
+			RateLimit:   auth.RateLimitRule{Burst: 3, RefillSeconds: 20},
+			Handler:     sonarrHandler(handleSonarrCommand),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "job",
+					Description: "Job to run",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Rescan series folders", Value: "rescan"},
+						{Name: "Refresh series information", Value: "refresh"},
+						{Name: "RSS sync", Value: "rss"},
+						{Name: "Backup", Value: "backup"},
+						{Name: "Search missing episodes", Value: "missingsearch"},
+					},
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Limit rescan, refresh or missingsearch to one series",
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarrinfo",
+			Category:    CategorySonarr,
+			Usage:       "<series_name|series_id>",
+			Description: "Show the details of a series with a per-season breakdown",
+			Examples:    []string{"sonarrinfo severance", "sonarrinfo 12"},
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // fetches every series from Sonarr when the cache is stale
+			Handler:     sonarrHandler(handleSonarrInfo),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Series name or ID",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarrmonitor",
+			Category:    CategorySonarr,
+			Usage:       "<series_name|series_id> [season <n>|all] on|off",
+			Description: "Turn monitoring of a series, one of its seasons or all of its seasons on or off",
+			Examples:    []string{"sonarrmonitor severance off", "sonarrmonitor severance season 2 on", "sonarrmonitor 12 all on"},
+			Permission:  PermissionTrusted,
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // fetches every series from Sonarr when the cache is stale
+			Handler:     sonarrHandler(handleSonarrMonitor),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Series name or ID",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "state",
+					Description: "Monitor or stop monitoring",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "on", Value: "on"},
+						{Name: "off", Value: "off"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "season",
+					Description: "Season number, or all for every season, instead of the whole series",
+				},
+			},
+			Flags:        []string{"season"},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarrdelete",
+			Category:    CategorySonarr,
+			Usage:       "<series_name|series_id>",
+			Description: "Delete a series from Sonarr, optionally with its files, after confirming",
+			Examples:    []string{"sonarrdelete severance", "sonarrdelete 12"},
+			Permission:  PermissionAdmin,
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // fetches every series from Sonarr when the cache is stale
+			Handler:     sonarrHandler(handleSonarrDelete),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Series name or ID",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarrhistory",
+			Category:    CategorySonarr,
+			Usage:       "[series_name|series_id] [--since <duration>]",
+			Description: "Show the recent grabs, imports, failures and deletions with their release details",
+			Examples:    []string{"sonarrhistory", "sonarrhistory --since 24h", "sonarrhistory severance --since 7d"},
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // fetches the history, and every series from Sonarr when the cache is stale
+			Handler:     sonarrHandler(handleSonarrHistory),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Only show the history of this series",
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "since",
+					Description: "Only show events newer than this, e.g. 90m, 24h or 7d",
+				},
+			},
+			Flags:        []string{"since"},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarrstatus",
+			Category:    CategorySonarr,
+			Description: "Show the Sonarr version, uptime, health issues and root folder free space",
+			Examples:    []string{"sonarrstatus"},
+			Handler:     sonarrHandler(handleSonarrStatus),
+		},
+		{
+			Name:        "sonarrreleases",
+			Category:    CategorySonarr,
+			Usage:       "<series_name|series_id> <SxxEyy>",
+			Description: "Search the indexers for the releases of an episode and grab one (grabbing needs trusted)",
+			Examples:    []string{"sonarrreleases severance S02E03", "sonarrreleases 12 s01e01"},
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 30}, // searches every indexer
+			Handler:     sonarrHandler(handleSonarrReleases),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Series name or ID",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "episode",
+					Description: "Episode code, e.g. S01E02",
+					Required:    true,
+				},
+			},
+			Autocomplete: autocompleteSonarrLocalSeries,
+		},
+		{
+			Name:        "sonarrprofiles",
+			Category:    CategorySonarr,
+			Description: "List the Sonarr quality profiles and how many series use each",
+			Examples:    []string{"sonarrprofiles"},
+			Handler:     sonarrHandler(handleSonarrProfiles),
+		},
+		{
+			Name:        "sonarrtags",
+			Category:    CategorySonarr,
+			Description: "List the Sonarr tags and how many series carry each",
+			Examples:    []string{"sonarrtags"},
+			Handler:     sonarrHandler(handleSonarrTags),
+		},
+		{
+			Name:        "sonarrroots",
+			Category:    CategorySonarr,
+			Description: "List the Sonarr root folders and their free space",
+			Examples:    []string{"sonarrroots"},
+			Handler:     sonarrHandler(handleSonarrRoots),
+		},
+		{
+			Name:        "sonarrset",
+			Category:    CategorySonarr,
+			Usage:       "<series_name|series_id> profile <name|id> | tag [+|-]<label> | root <path|id>",
+			Description: "Change the quality profile or tags of a series, or move it to another root folder",
+			Examples: []string{
+				"sonarrset severance profile HD-1080p",
+				"sonarrset severance tag +4k",
+				"sonarrset severance tag -4k",
+				"sonarrset 12 root /mnt/tv2",
+			},
+			Permission: PermissionTrusted,
+			RateLimit:  auth.RateLimitRule{Burst: 3, RefillSeconds: 10},
+			Handler:    sonarrHandler(handleSonarrSet),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "series",
+					Description:  "Series name or ID",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "setting",
+					Description: "Setting to change",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Quality profile", Value: "profile"},
+						{Name: "Tag (+label to add, -label to remove)", Value: "tag"},
+						{Name: "Root folder (moves the files)", Value: "root"},
+					},
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "value",
+					Description:  "Profile name, +tag or -tag, or root folder path",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteSonarrSet,
+		},
+		{
+			Name:        "radarrlookup",
+			Category:    CategoryRadarr,
+			Usage:       "<movie_name>",
+			Description: "Look up a movie by name on Radarr",
+			Examples:    []string{"radarrlookup dune"},
+			Handler:     radarrHandler(handleRadarrMovieLookup),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "term",
+					Description: "Movie name to look up",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "radarrls",
+			Category:    CategoryRadarr,
+			Usage:       "<movie_name>",
+			Description: "Search the movies already added to the local Radarr instance",
+			Examples:    []string{"radarrls dune"},
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // fetches every movie from Radarr when the cache is stale
+			Handler:     radarrHandler(handleRadarrLocalMovieSearch),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "movie",
+					Description:  "Movie name (or part of it)",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+			Autocomplete: autocompleteRadarrLocalMovies,
+		},
+		{
+			Name:        "radarradd",
+			Category:    CategoryRadarr,
+			Usage:       "<movie_name>",
+			Description: "Look up a movie and add it to Radarr, picking the quality profile, root folder and availability",
+			Examples:    []string{"radarradd dune part two"},
+			Permission:  PermissionTrusted,
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // looks the movie up on TMDb
+			Handler:     radarrHandler(handleRadarrAdd),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "term",
+					Description: "Movie name to look up",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "radarrqueue",
+			Category:    CategoryRadarr,
+			Usage:       "[remove <id> [blocklist]]",
+			Description: "Show the Radarr download queue, or remove a stuck item (remove needs trusted)",
+			Examples:    []string{"radarrqueue", "radarrqueue remove 1234", "radarrqueue remove 1234 blocklist"},
+			Handler:     radarrHandler(handleRadarrQueue),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "list",
+					Description: "Show the download queue",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "remove",
+					Description: "Remove an item from the queue and the download client",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "id",
+							Description: "Queue item ID",
+							Required:    true,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "blocklist",
+							Description: "Add the release to the blocklist",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "radarrcal",
+			Category:    CategoryRadarr,
+			Usage:       "[days]",
+			Description: "List upcoming cinema, digital and physical movie releases (7 days by default, up to 31)",
+			Examples:    []string{"radarrcal", "radarrcal 14"},
+			Handler:     radarrHandler(handleRadarrCalendar),
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "days",
+					Description: "Number of days to show",
+					MinValue:    &minCalendarDays,
+					MaxValue:    maxCalendarDays,
+				},
+			},
+		},
+		{
+			Name:        "indexers",
+			Category:    CategoryProwlarr,
+			Description: "List the Prowlarr indexers with their state, last failure and average response time",
+			Examples:    []string{"indexers"},
+			Handler:     prowlarrHandler(handleIndexers),
+		},
+		{
+			Name:        "search",
+			Category:    CategoryProwlarr,
+			Usage:       "<query> [--cat tv|movies]",
+			Description: "Search every Prowlarr indexer and show the best results",
+			Examples:    []string{"search severance s02e01", "search dune part two --cat movies"},
+			Permission:  PermissionTrusted,
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 30}, // queries every indexer
+			Handler:     prowlarrHandler(handleSearch),
+			Flags:       []string{"cat"},
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "query",
+					Description: "Text to search for",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "cat",
+					Description: "Only search this category",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "TV", Value: "tv"},
+						{Name: "Movies", Value: "movies"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "dl",
+			Category:    CategoryDownloads,
+			Usage:       "[status] | pause|resume <id|all> | delete <id> [--files] | slow on|off",
+			Description: "Show the qBittorrent and SABnzbd transfers, speeds and free space, or control them (needs trusted)",
+			Examples:    []string{"dl", "dl pause all", "dl resume 3f2a9c1b", "dl delete 3f2a9c1b --files", "dl slow on"},
+			Handler:     handleDownloads,
+			Flags:       []string{"files"},
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "status",
+					Description: "Show the transfers, speeds and free space",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "pause",
+					Description: "Pause a transfer, or all of them",
+					Options:     []*discordgo.ApplicationCommandOption{transferIDOption()},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "resume",
+					Description: "Resume a transfer, or all of them",
+					Options:     []*discordgo.ApplicationCommandOption{transferIDOption()},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "delete",
+					Description: "Delete a transfer from its download client",
+					Options: []*discordgo.ApplicationCommandOption{
+						transferIDOption(),
+						{
+							Type:        discordgo.ApplicationCommandOptionBoolean,
+							Name:        "files",
+							Description: "Delete the downloaded files as well",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Name:        "slow",
+					Description: "Turn the reduced speed limit on or off",
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "state",
+							Description: "Slow mode",
+							Required:    true,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "on", Value: "on"},
+								{Name: "off", Value: "off"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:        "dbver",
+			Category:    CategoryDatabase,
+			Description: "Return the database version",
+			Examples:    []string{"dbver"},
+			Permission:  PermissionTrusted,
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // opens a database connection
+			Handler:     handleDatabaseVersion,
+		},
+		{
+			Name:        "add",
+			Category:    CategoryDatabase,
+			Usage:       "<manga_name>",
+			Description: "Insert or update a manga name in the database",
+			Examples:    []string{"add One Piece"},
+			Permission:  PermissionTrusted,
+			RateLimit:   auth.RateLimitRule{Burst: 2, RefillSeconds: 10}, // opens a database connection
+			Handler:     handleDbInsertMangaName,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "Manga name",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "wip",
+			Category:    CategoryFirewall,
+			Description: "Return the firewall WAN interface IP address",
+			Examples:    []string{"wip"},
+			Permission:  PermissionAdmin,
+			Handler:     handleCurrentWanIP,
+		},
+	}
+}
+
+// UsageLine returns the usage of the command including the prefix, e.g. "!sonarrls <series_name>"
+func (c *Command) UsageLine(prefix string) string {
+	return strings.TrimSpace(prefix + c.Name + " " + c.Usage)
+}
+
+// sendUsage replies with the registered usage of the named command
+func sendUsage(s *discordgo.Session, channelID, name string) {
+	cmd, ok := Commands.Lookup(name)
+	if !ok {
+		return
+	}
+	s.ChannelMessageSend(channelID, fmt.Sprintf("Usage: `%s` - %s", cmd.UsageLine(Commands.Prefix), cmd.Description))
+}
+
+// helpOverviewEmbed lists every command grouped by category
+func helpOverviewEmbed() *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "Available commands",
+		Description: fmt.Sprintf("Type `%shelp <command>` for details on a command.", Commands.Prefix),
+	}
+
+	for _, category := range categories {
+		var lines []string
+		for _, cmd := range Commands.Commands() {
+			if cmd.Category == category {
+				lines = append(lines, fmt.Sprintf("`%s%s` - %s", Commands.Prefix, cmd.Name, cmd.Description))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		// A field holds at most 1024 characters, a long category continues in further fields
+		name, value := string(category), ""
+		for _, line := range lines {
+			if value != "" && len(value)+len("\n")+len(line) > discordFieldLimit {
+				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: value})
+				name, value = string(category)+" (continued)", ""
+			}
+			if value != "" {
+				value += "\n"
+			}
+			value += line
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: name, Value: value})
+	}
+
+	return embed
+}
+
+// helpCommandEmbed shows the metadata of a single command
+func helpCommandEmbed(cmd *Command) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       Commands.Prefix + cmd.Name,
+		Description: cmd.Description,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Usage", Value: fmt.Sprintf("`%s`", cmd.UsageLine(Commands.Prefix))},
+			{Name: "Category", Value: string(cmd.Category), Inline: true},
+			{Name: "Permission", Value: requiredPermission(cmd).String(), Inline: true},
+		},
+	}
+
+	if len(cmd.Aliases) > 0 {
+		var aliases []string
+		for _, alias := range cmd.Aliases {
+			aliases = append(aliases, fmt.Sprintf("`%s%s`", Commands.Prefix, alias))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: "Aliases", Value: strings.Join(aliases, ", "), Inline: true,
+		})
+	}
+
+	if len(cmd.Examples) > 0 {
+		var examples []string
+		for _, example := range cmd.Examples {
+			examples = append(examples, fmt.Sprintf("`%s%s`", Commands.Prefix, example))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name: "Examples", Value: strings.Join(examples, "\n"),
+		})
+	}
+
+	return embed
+}
+
+// autocompleteCommandName offers the registered command names containing the typed value
+func autocompleteCommandName(s *discordgo.Session, i *discordgo.InteractionCreate, value string) []*discordgo.ApplicationCommandOptionChoice {
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, cmd := range Commands.Commands() {
+		if !strings.Contains(cmd.Name, strings.ToLower(value)) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: cmd.Name, Value: cmd.Name})
+		// Discord accepts at most 25 choices
+		if len(choices) == 25 {
+			break
+		}
+	}
+	return choices
+}
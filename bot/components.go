@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ComponentHandler handles a button press or select menu choice on a message sent by a command
+type ComponentHandler func(s *discordgo.Session, i *discordgo.InteractionCreate, action string, values []string)
+
+// componentHandlers maps the first part of a component custom ID ("sonarradd" in "sonarradd:profile")
+// to its handler, the rest of the custom ID is passed on as the action.
+var componentHandlers = map[string]ComponentHandler{
+	"sonarradd":      handleArrAddComponent,
+	"sonarrmissing":  handleSonarrMissingComponent,
+	"sonarrdelete":   handleSonarrDeleteComponent,
+	"sonarrreleases": handleSonarrReleasesComponent,
+	"radarradd":      handleArrAddComponent,
+}
+
+// handleComponent dispatches a message component interaction to the handler registered for its custom ID
+func handleComponent(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.MessageComponentData()
+
+	name, action, _ := strings.Cut(data.CustomID, ":")
+	handler, ok := componentHandlers[name]
+	if !ok {
+		log.Println("Unknown component:", data.CustomID)
+		respondEphemeral(s, i, "This message is no longer active.")
+		return
+	}
+
+	handler(s, i, action, data.Values)
+}
+
+// interactionUser returns the user who triggered an interaction, in a guild or in a DM
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// respondUpdate replaces the content and components of the message the component belongs to
+func respondUpdate(s *discordgo.Session, i *discordgo.InteractionCreate, content string, components []discordgo.MessageComponent) {
+	if components == nil {
+		// an empty list removes the components, nil would leave them in place
+		components = []discordgo.MessageComponent{}
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    content,
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.Println("Error responding to interaction:", err)
+	}
+}
+
+// componentSession is the state of an interactive message, owned by the user who ran the command
+type componentSession[T any] struct {
+	userID  string
+	expires time.Time
+	value   T
+}
+
+// sessionStore keeps the state of interactive messages by message ID until they expire
+type sessionStore[T any] struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]*componentSession[T]
+}
+
+// newSessionStore creates a store whose sessions expire ttl after they are created
+func newSessionStore[T any](ttl time.Duration) *sessionStore[T] {
+	return &sessionStore[T]{
+		ttl:      ttl,
+		sessions: make(map[string]*componentSession[T]),
+	}
+}
+
+// put stores the state of a message, dropping any expired sessions on the way
+func (st *sessionStore[T]) put(messageID, userID string, value T) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range st.sessions {
+		if now.After(session.expires) {
+			delete(st.sessions, id)
+		}
+	}
+
+	st.sessions[messageID] = &componentSession[T]{userID: userID, expires: now.Add(st.ttl), value: value}
+}
+
+// get returns the state of the message an interaction belongs to.
+// When the session has expired or belongs to another user the interaction is answered and ok is false.
+func (st *sessionStore[T]) get(s *discordgo.Session, i *discordgo.InteractionCreate) (value T, ok bool) {
+	st.mu.Lock()
+	session, found := st.sessions[i.Message.ID]
+	if found && time.Now().After(session.expires) {
+		delete(st.sessions, i.Message.ID)
+		found = false
+	}
+	st.mu.Unlock()
+
+	if !found {
+		respondUpdate(s, i, i.Message.Content+"\n\n*This request has expired, run the command again.*", nil)
+		return value, false
+	}
+
+	if user := interactionUser(i); user == nil || user.ID != session.userID {
+		respondEphemeral(s, i, fmt.Sprintf("Only <@%s> can use these controls.", session.userID))
+		return value, false
+	}
+
+	return session.value, true
+}
+
+// remove forgets the state of a message once it is finished with
+func (st *sessionStore[T]) remove(messageID string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	delete(st.sessions, messageID)
+}
@@ -0,0 +1,308 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"main/api"
+	"main/auth"
+	"main/downloader"
+	"main/format"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultDownloaderTimeout is used when downloaders.timeout_seconds is not set in the config file
+const defaultDownloaderTimeout = 15 * time.Second
+
+// defaultSabnzbdSlowLimit is the SABnzbd speed limit of "!dl slow on" when sabnzbd_slow_limit is not set, in percent
+const defaultSabnzbdSlowLimit = "20"
+
+// transferLimit is the number of transfers !dl status lists per client, the rest are counted
+const transferLimit = 15
+
+// downloadClients are the download clients created by Init, empty when none is configured
+var downloadClients []downloader.Client
+
+// loadDownloaders creates the qBittorrent and SABnzbd clients whose URL is set in the config file
+func loadDownloaders(config auth.Config, creds auth.Auth) {
+	downloadClients = nil
+	httpClient := &http.Client{Timeout: secondsOrDefault(config.Downloaders.TimeoutSeconds, defaultDownloaderTimeout)}
+
+	if config.Downloaders.QBittorrentURL != "" {
+		downloadClients = append(downloadClients, downloader.NewQBittorrent(
+			config.Downloaders.QBittorrentURL, config.Downloaders.QBittorrentUser, creds.QBittorrentPassword, httpClient))
+	}
+
+	if config.Downloaders.SabnzbdURL != "" {
+		slowLimit := config.Downloaders.SabnzbdSlowLimit
+		if slowLimit == "" {
+			slowLimit = defaultSabnzbdSlowLimit
+		}
+		downloadClients = append(downloadClients, downloader.NewSABnzbd(
+			config.Downloaders.SabnzbdURL, creds.SabnzbdApiKey, slowLimit, httpClient))
+	}
+}
+
+// downloaderErrorMessage logs a download client error and returns the message shown in Discord
+func downloaderErrorMessage(client downloader.Client, action string, err error) string {
+	log.Printf("Error %s on %s: %v", action, client.Name(), err)
+
+	switch {
+	case errors.Is(err, api.ErrUnauthorized):
+		return fmt.Sprintf("Error %s: %s rejected the credentials.", action, client.Name())
+	case errors.Is(err, api.ErrNotFound):
+		return fmt.Sprintf("Error %s: not found on %s.", action, client.Name())
+	default:
+		return fmt.Sprintf("Error %s on %s: %s", action, client.Name(), err)
+	}
+}
+
+// handleDownloads responds to the !dl command, showing the download clients or pausing, resuming and
+// deleting transfers and toggling the slow speed limit
+func handleDownloads(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	log.Println("Download client arguments:", args)
+
+	if len(downloadClients) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "No download client is configured, check the config and credentials files.")
+		return
+	}
+
+	if len(args) == 0 || args[0] == "status" {
+		sendDownloadStatus(s, m)
+		return
+	}
+
+	flags, rest := splitFlags(args[1:])
+	if len(rest) != 1 {
+		sendUsage(s, m.ChannelID, "dl")
+		return
+	}
+
+	switch args[0] {
+	case "pause", "resume":
+		if !requirePermission(s, m, PermissionTrusted, args[0]+" downloads") {
+			return
+		}
+		toggleTransfers(s, m, rest[0], args[0] == "pause")
+	case "delete":
+		if !requirePermission(s, m, PermissionTrusted, "delete downloads") {
+			return
+		}
+		deleteTransfer(s, m, rest[0], flags["files"] == "true")
+	case "slow":
+		if rest[0] != "on" && rest[0] != "off" {
+			sendUsage(s, m.ChannelID, "dl")
+			return
+		}
+		if !requirePermission(s, m, PermissionTrusted, "change the download speed limit") {
+			return
+		}
+		setSlowMode(s, m, rest[0] == "on")
+	default:
+		sendUsage(s, m.ChannelID, "dl")
+	}
+}
+
+// sendDownloadStatus sends the speeds, free space and transfers of each download client
+func sendDownloadStatus(s *discordgo.Session, m *discordgo.MessageCreate) {
+	var message string
+	for _, client := range downloadClients {
+		status, err := client.Status()
+		if err != nil {
+			message += downloaderErrorMessage(client, "fetching the status", err) + "\n\n"
+			continue
+		}
+
+		header := []string{fmt.Sprintf("**%s**", client.Name()), "⬇️ " + formatSpeed(status.DownloadSpeed)}
+		if status.UploadSpeed > 0 {
+			header = append(header, "⬆️ "+formatSpeed(status.UploadSpeed))
+		}
+		if status.FreeSpace >= 0 {
+			header = append(header, format.Bytes(status.FreeSpace)+" free")
+		}
+		if status.SlowMode {
+			header = append(header, "🐢 slow mode")
+		}
+		if status.Paused {
+			header = append(header, "⏸️ paused")
+		}
+		message += strings.Join(header, " · ") + "\n"
+
+		if len(status.Transfers) == 0 {
+			message += "Nothing downloading.\n"
+		}
+		for index, transfer := range status.Transfers {
+			if index == transferLimit {
+				message += fmt.Sprintf("...and %d more\n", len(status.Transfers)-transferLimit)
+				break
+			}
+			message += transferLine(transfer)
+		}
+		message += "\n"
+	}
+
+	message += fmt.Sprintf("Control a transfer with `%sdl pause|resume <id|all>` or `%sdl delete <id> [--files]`.",
+		Commands.Prefix, Commands.Prefix)
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// transferLine describes a transfer with its short ID, progress, speed and ETA
+func transferLine(transfer downloader.Transfer) string {
+	icon := "⬇️"
+	if transfer.Paused {
+		icon = "⏸️"
+	}
+
+	details := []string{fmt.Sprintf("%.1f%% of %s", transfer.Progress, format.Bytes(transfer.Size))}
+	if transfer.DownloadSpeed > 0 {
+		details = append(details, formatSpeed(transfer.DownloadSpeed))
+	}
+	if transfer.ETA > 0 {
+		details = append(details, "ETA "+formatUptime(transfer.ETA))
+	}
+	details = append(details, transfer.State)
+
+	return fmt.Sprintf("`%s` %s %s\n- %s\n", transfer.ShortID(), icon, transfer.Name, strings.Join(details, " · "))
+}
+
+// formatSpeed formats a speed in bytes per second, e.g. "1.2 MB/s"
+func formatSpeed(bytesPerSecond int64) string {
+	return format.Bytes(bytesPerSecond) + "/s"
+}
+
+// toggleTransfers pauses or resumes a transfer, or every transfer of every client when id is all
+func toggleTransfers(s *discordgo.Session, m *discordgo.MessageCreate, id string, pause bool) {
+	icon, action, done := "▶️", "resuming", "resumed"
+	if pause {
+		icon, action, done = "⏸️", "pausing", "paused"
+	}
+
+	// apply pauses or resumes on the given client
+	apply := func(client downloader.Client, id string) error {
+		if pause {
+			return client.Pause(id)
+		}
+		return client.Resume(id)
+	}
+
+	if strings.EqualFold(id, downloader.AllTransfers) {
+		var message string
+		for _, client := range downloadClients {
+			if err := apply(client, downloader.AllTransfers); err != nil {
+				message += downloaderErrorMessage(client, action+" every download", err) + "\n"
+				continue
+			}
+			message += fmt.Sprintf("%s Every download %s on %s.\n", icon, done, client.Name())
+		}
+		log.Printf("Every download %s by %s", done, m.Author.Username)
+		s.ChannelMessageSend(m.ChannelID, message)
+		return
+	}
+
+	client, transfer, ok := resolveTransfer(s, m.ChannelID, id)
+	if !ok {
+		return
+	}
+
+	if err := apply(client, transfer.ID); err != nil {
+		s.ChannelMessageSend(m.ChannelID, downloaderErrorMessage(client, fmt.Sprintf("%s %s", action, transfer.Name), err))
+		return
+	}
+
+	log.Printf("Download %s on %s %s by %s", transfer.Name, client.Name(), done, m.Author.Username)
+	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("%s **%s** %s on %s.", icon, transfer.Name, done, client.Name()))
+}
+
+// deleteTransfer removes a transfer from its client, deleting the downloaded files when asked
+func deleteTransfer(s *discordgo.Session, m *discordgo.MessageCreate, id string, deleteFiles bool) {
+	client, transfer, ok := resolveTransfer(s, m.ChannelID, id)
+	if !ok {
+		return
+	}
+
+	if err := client.Delete(transfer.ID, deleteFiles); err != nil {
+		s.ChannelMessageSend(m.ChannelID, downloaderErrorMessage(client, fmt.Sprintf("deleting %s", transfer.Name), err))
+		return
+	}
+
+	log.Printf("Download %s deleted from %s by %s (files: %v)", transfer.Name, client.Name(), m.Author.Username, deleteFiles)
+	message := fmt.Sprintf("🗑️ Deleted **%s** from %s.", transfer.Name, client.Name())
+	if deleteFiles {
+		message += " The downloaded files were deleted."
+	}
+	s.ChannelMessageSend(m.ChannelID, message)
+}
+
+// setSlowMode turns the reduced speed limit of every client on or off
+func setSlowMode(s *discordgo.Session, m *discordgo.MessageCreate, on bool) {
+	state := "off"
+	if on {
+		state = "on"
+	}
+
+	var message string
+	for _, client := range downloadClients {
+		if err := client.SetSlowMode(on); err != nil {
+			message += downloaderErrorMessage(client, "changing the speed limit", err) + "\n"
+			continue
+		}
+		message += fmt.Sprintf("🐢 Slow mode %s on %s.\n", state, client.Name())
+	}
+
+	log.Printf("Download slow mode turned %s by %s", state, m.Author.Username)
+	s.ChannelMessageSend(m.ChannelID, message)
+}
+
+// transferIDOption is the id option of the !dl subcommands
+func transferIDOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "id",
+		Description: "Transfer ID shown by /dl status, or all",
+		Required:    true,
+	}
+}
+
+// resolveTransfer finds the transfer whose ID or short ID starts with id across the clients. When none or
+// several match the channel is told so.
+func resolveTransfer(s *discordgo.Session, channelID, id string) (downloader.Client, downloader.Transfer, bool) {
+	type match struct {
+		client   downloader.Client
+		transfer downloader.Transfer
+	}
+
+	var matches []match
+	for _, client := range downloadClients {
+		status, err := client.Status()
+		if err != nil {
+			s.ChannelMessageSend(channelID, downloaderErrorMessage(client, "fetching the transfers", err))
+			continue
+		}
+		for _, transfer := range status.Transfers {
+			if strings.EqualFold(transfer.ID, id) {
+				return client, transfer, true
+			}
+			if len(id) >= 4 && strings.HasPrefix(strings.ToLower(transfer.ShortID()), strings.ToLower(id)) {
+				matches = append(matches, match{client, transfer})
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		s.ChannelMessageSend(channelID, fmt.Sprintf("No download with the ID `%s`, see `%sdl status`.", id, Commands.Prefix))
+	case 1:
+		return matches[0].client, matches[0].transfer, true
+	default:
+		message := fmt.Sprintf("Several downloads match `%s`, use a longer ID:\n", id)
+		for _, candidate := range matches {
+			message += fmt.Sprintf("- `%s` %s (%s)\n", candidate.transfer.ID, candidate.transfer.Name, candidate.client.Name())
+		}
+		sendMessageChunks(s, channelID, message)
+	}
+	return nil, downloader.Transfer{}, false
+}
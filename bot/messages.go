@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// discordMessageLimit is the maximum length of a Discord message
+const discordMessageLimit = 2000
+
+// discordFieldLimit is the maximum length of the value of an embed field
+const discordFieldLimit = 1024
+
+// sendMessageChunks sends message to the channel, split into chunks of 2000 characters or less.
+// Chunks are cut at the last newline so entries are not split across messages.
+func sendMessageChunks(s *discordgo.Session, channelID, message string) {
+	for len(message) > discordMessageLimit {
+		// Find the last line break within 2000 characters
+		truncatedMessage := message[:discordMessageLimit]
+		lastNewlineIndex := strings.LastIndex(truncatedMessage, "\n")
+
+		if lastNewlineIndex == -1 {
+			// If there's no newline in the first 2000 characters, send the whole chunk
+			s.ChannelMessageSend(channelID, truncatedMessage)
+			message = message[discordMessageLimit:]
+		} else {
+			// Send the chunk up to the last complete line
+			s.ChannelMessageSend(channelID, message[:lastNewlineIndex+1])
+			message = message[lastNewlineIndex+1:]
+		}
+	}
+
+	// Send the remaining message (less than 2000 characters)
+	if len(message) > 0 {
+		s.ChannelMessageSend(channelID, message)
+	}
+}
+
+// secondsOrDefault converts a number of seconds from the config file, using fallback when unset
+func secondsOrDefault(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// minutesOrDefault converts a number of minutes from the config file, using fallback when unset
+func minutesOrDefault(minutes int, fallback time.Duration) time.Duration {
+	if minutes <= 0 {
+		return fallback
+	}
+	return time.Duration(minutes) * time.Minute
+}
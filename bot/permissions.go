@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"main/auth"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// permissionConfig holds the role, user and channel rules loaded from the config file by Init
+var permissionConfig auth.PermissionConfig
+
+// parsePermission converts a permission level name from the config file into a Permission
+func parsePermission(level string) (Permission, error) {
+	switch strings.ToLower(level) {
+	case "everyone":
+		return PermissionEveryone, nil
+	case "trusted":
+		return PermissionTrusted, nil
+	case "admin":
+		return PermissionAdmin, nil
+	}
+	return PermissionEveryone, fmt.Errorf("unknown permission level %q", level)
+}
+
+// requiredPermission returns the level needed to run cmd, applying any override from the config file
+func requiredPermission(cmd *Command) Permission {
+	level, ok := permissionConfig.Commands[cmd.Name]
+	if !ok {
+		return cmd.Permission
+	}
+
+	permission, err := parsePermission(level)
+	if err != nil {
+		// Never lower the declared level because of a typo in the config
+		log.Printf("Ignoring permission override for %s: %v", cmd.Name, err)
+		return cmd.Permission
+	}
+	return permission
+}
+
+// ruleMatches reports whether the author of m matches the users or roles of rule, in one of its channels.
+// An empty channel list matches every channel.
+func ruleMatches(rule auth.PermissionRule, m *discordgo.MessageCreate) bool {
+	if len(rule.Channels) > 0 && !slices.Contains(rule.Channels, m.ChannelID) {
+		return false
+	}
+
+	if slices.Contains(rule.Users, m.Author.ID) {
+		return true
+	}
+
+	if m.Member != nil {
+		for _, role := range m.Member.Roles {
+			if slices.Contains(rule.Roles, role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDenied reports whether the author or channel of m is blocked from running any command
+func isDenied(m *discordgo.MessageCreate) bool {
+	deny := permissionConfig.Deny
+	if slices.Contains(deny.Channels, m.ChannelID) || slices.Contains(deny.Users, m.Author.ID) {
+		return true
+	}
+
+	if m.Member != nil {
+		for _, role := range m.Member.Roles {
+			if slices.Contains(deny.Roles, role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// userPermission returns the highest permission level the author of m holds in the channel of m
+func userPermission(m *discordgo.MessageCreate) Permission {
+	switch {
+	case ruleMatches(permissionConfig.Admin, m):
+		return PermissionAdmin
+	case ruleMatches(permissionConfig.Trusted, m):
+		return PermissionTrusted
+	default:
+		return PermissionEveryone
+	}
+}
+
+// authorize checks that the author of m may run cmd, returning the denial message when not.
+// Denials are logged with the user, channel and command involved.
+func authorize(m *discordgo.MessageCreate, cmd *Command) error {
+	if isDenied(m) {
+		log.Printf("Permission denied: %s (%s) is blocked from running %s in channel %s",
+			m.Author.Username, m.Author.ID, cmd.Name, m.ChannelID)
+		return errors.New("⛔ You are not allowed to run commands here.")
+	}
+
+	required := requiredPermission(cmd)
+	if held := userPermission(m); held < required {
+		log.Printf("Permission denied: %s (%s) holds %s but %s requires %s in channel %s",
+			m.Author.Username, m.Author.ID, held, cmd.Name, required, m.ChannelID)
+		return fmt.Errorf("⛔ You need the `%s` permission to run `%s%s`.", required, Commands.Prefix, cmd.Name)
+	}
+
+	return nil
+}
+
+// requirePermission checks the author of m holds level for an action inside a command,
+// such as removing a queue item, telling the channel when they do not.
+func requirePermission(s *discordgo.Session, m *discordgo.MessageCreate, level Permission, action string) bool {
+	if held := userPermission(m); held < level {
+		log.Printf("Permission denied: %s (%s) holds %s but %s requires %s in channel %s",
+			m.Author.Username, m.Author.ID, held, action, level, m.ChannelID)
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("⛔ You need the `%s` permission to %s.", level, action))
+		return false
+	}
+	return true
+}
+
+// requireComponentPermission checks the user pressing a button holds level for the action it triggers,
+// answering them privately when they do not.
+func requireComponentPermission(s *discordgo.Session, i *discordgo.InteractionCreate, level Permission, action string) bool {
+	m := interactionMessage(i, "")
+	if held := userPermission(m); held < level {
+		log.Printf("Permission denied: %s (%s) holds %s but %s requires %s in channel %s",
+			m.Author.Username, m.Author.ID, held, action, level, m.ChannelID)
+		respondEphemeral(s, i, fmt.Sprintf("⛔ You need the `%s` permission to %s.", level, action))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/api"
+	"main/auth"
+	"main/format"
+	"main/prowlarr"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultProwlarrTimeout is used when prowlarr_timeout_seconds is not set in the config file
+const defaultProwlarrTimeout = 15 * time.Second
+
+// Number of results !search asks Prowlarr for, and how many of the best it shows
+const (
+	searchFetchLimit  = 100
+	searchResultLimit = 10
+)
+
+// searchCategories maps the !search --cat values to Newznab categories
+var searchCategories = map[string]int{
+	"tv":     prowlarr.CategoryTV,
+	"movies": prowlarr.CategoryMovies,
+}
+
+// prowlarrClient is the Prowlarr API client created by Init, nil when Prowlarr is not configured
+var prowlarrClient prowlarr.Service
+
+// ProwlarrCommandHandler defines the signature of a Prowlarr command, called with the Prowlarr client
+type ProwlarrCommandHandler func(s *discordgo.Session, m *discordgo.MessageCreate, client prowlarr.Service, args []string)
+
+// prowlarrHandler adapts a Prowlarr command to a CommandHandler, telling the channel when Prowlarr is not configured
+func prowlarrHandler(handler ProwlarrCommandHandler) CommandHandler {
+	return func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+		if prowlarrClient == nil {
+			s.ChannelMessageSend(m.ChannelID, "Prowlarr is not configured, check the config and credentials files.")
+			return
+		}
+		handler(s, m, prowlarrClient, args)
+	}
+}
+
+// loadProwlarr creates the Prowlarr client when prowlarr_instance is set in the config file
+func loadProwlarr(config auth.Config, creds auth.Auth) {
+	if config.ProwlarrInstance == "" {
+		return
+	}
+
+	prowlarrClient = prowlarr.NewClient(prowlarr.Config{
+		BaseURL: prowlarr.BaseURL(config.ProwlarrInstance, config.ProwlarrPort),
+		APIKey:  creds.ProwlarrApiToken,
+		Timeout: secondsOrDefault(config.ProwlarrTimeoutSeconds, defaultProwlarrTimeout),
+		Retry: api.RetryPolicy{
+			Retries: config.ProwlarrRetries,
+			Backoff: time.Second,
+		},
+	})
+}
+
+// handleIndexers responds to the !indexers command with the state, last failure and response time of each indexer
+func handleIndexers(s *discordgo.Session, m *discordgo.MessageCreate, client prowlarr.Service, args []string) {
+	indexers, err := client.Indexers()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Prowlarr", "fetching indexers from Prowlarr", err))
+		return
+	}
+	statuses, err := client.IndexerStatuses()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Prowlarr", "fetching indexer statuses from Prowlarr", err))
+		return
+	}
+	// The statistics only add the response times, the list is still useful without them
+	stats, err := client.IndexerStats()
+	if err != nil {
+		log.Println("Error fetching indexer statistics from Prowlarr:", err)
+	}
+
+	if len(indexers) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "Prowlarr has no indexers.")
+		return
+	}
+
+	statusByIndexer := make(map[int]prowlarr.IndexerStatus)
+	for _, status := range statuses {
+		statusByIndexer[status.IndexerID] = status
+	}
+	statsByIndexer := make(map[int]prowlarr.IndexerStats)
+	for _, stat := range stats {
+		statsByIndexer[stat.IndexerID] = stat
+	}
+
+	sort.Slice(indexers, func(i, j int) bool {
+		return strings.ToLower(indexers[i].Name) < strings.ToLower(indexers[j].Name)
+	})
+
+	var message string
+	for _, indexer := range indexers {
+		status, failed := statusByIndexer[indexer.ID]
+		disabled := failed && status.DisabledTill != nil && status.DisabledTill.After(time.Now())
+
+		icon := "✅"
+		switch {
+		case !indexer.Enable:
+			icon = "⏸️"
+		case disabled:
+			icon = "⛔"
+		case failed:
+			icon = "⚠️"
+		}
+
+		message += fmt.Sprintf("%s **%s** (%s, %s)", icon, indexer.Name, indexer.Protocol, indexer.Privacy)
+		if stat, ok := statsByIndexer[indexer.ID]; ok && stat.NumberOfQueries > 0 {
+			message += fmt.Sprintf(" · %d ms avg · %d queries, %d failed", stat.AverageResponseTime, stat.NumberOfQueries, stat.NumberOfFailedQueries)
+		}
+		message += "\n"
+
+		if !indexer.Enable {
+			message += "- Disabled in Prowlarr\n"
+		}
+		if failed && status.MostRecentFailure != nil {
+			message += fmt.Sprintf("- Last failure: %s\n", status.MostRecentFailure.In(botLocation).Format("2 Jan 15:04"))
+		}
+		if disabled {
+			message += fmt.Sprintf("- Backing off until %s\n", status.DisabledTill.In(botLocation).Format("2 Jan 15:04"))
+		}
+	}
+
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// handleSearch responds to the !search command with the best releases the indexers have for a query
+func handleSearch(s *discordgo.Session, m *discordgo.MessageCreate, client prowlarr.Service, args []string) {
+	log.Println("Prowlarr search arguments:", args)
+
+	flags, args := splitFlags(args, "cat")
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "search")
+		return
+	}
+
+	var categories []int
+	if name, ok := flags["cat"]; ok {
+		category, ok := searchCategories[strings.ToLower(name)]
+		if !ok {
+			sendUsage(s, m.ChannelID, "search")
+			return
+		}
+		categories = []int{category}
+	}
+
+	query := strings.Join(args, " ")
+	status, err := s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("🔎 Searching the indexers for **%s**...", query))
+	if err != nil {
+		log.Println("Error sending Prowlarr search message:", err)
+		return
+	}
+
+	results, err := client.Search(query, categories, searchFetchLimit)
+	if err != nil {
+		s.ChannelMessageEdit(m.ChannelID, status.ID, arrErrorMessage("Prowlarr", fmt.Sprintf("searching for %s", query), err))
+		return
+	}
+
+	if len(results) == 0 {
+		s.ChannelMessageEdit(m.ChannelID, status.ID, fmt.Sprintf("No results for **%s**.", query))
+		return
+	}
+
+	// Torrents are ranked by seeders and usenet posts by grabs, the better known releases first
+	sort.SliceStable(results, func(i, j int) bool {
+		return popularity(results[i]) > popularity(results[j])
+	})
+
+	header := fmt.Sprintf("Found %d results for **%s**", len(results), query)
+	if len(results) > searchResultLimit {
+		header += fmt.Sprintf(", showing the best %d", searchResultLimit)
+		results = results[:searchResultLimit]
+	}
+	s.ChannelMessageEdit(m.ChannelID, status.ID, header+":")
+
+	var message string
+	for index, result := range results {
+		message += fmt.Sprintf("`%d` **%s**\n> %s\n", index+1, result.Title, searchResultDetails(result))
+	}
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// popularity returns the seeders of a torrent or the grabs of a usenet post
+func popularity(result prowlarr.SearchResult) int {
+	if result.Seeders != nil {
+		return *result.Seeders
+	}
+	if result.Grabs != nil {
+		return *result.Grabs
+	}
+	return 0
+}
+
+// searchResultDetails returns the size, seeders or grabs, indexer, age and category of a result on one line
+func searchResultDetails(result prowlarr.SearchResult) string {
+	details := []string{format.Bytes(result.Size)}
+	if result.Seeders != nil {
+		details = append(details, fmt.Sprintf("%d seeders", *result.Seeders))
+	} else if result.Grabs != nil {
+		details = append(details, fmt.Sprintf("%d grabs", *result.Grabs))
+	}
+	details = append(details, result.Indexer, formatAge(time.Since(result.PublishDate).Hours()))
+	if len(result.Categories) > 0 {
+		details = append(details, result.Categories[0].Name)
+	}
+	return strings.Join(details, " · ")
+}
@@ -0,0 +1,242 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/api"
+	"main/auth"
+	"main/fuzzy"
+	"main/radarr"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultRadarrTimeout is used when radarr_timeout_seconds is not set in the config file
+const defaultRadarrTimeout = 15 * time.Second
+
+// defaultRadarrCacheInterval is used when radarr_cache_minutes is not set in the config file
+const defaultRadarrCacheInterval = 10 * time.Minute
+
+// radarrClient is the Radarr API client created by Init, with the movie list cached, nil when Radarr is not configured
+var radarrClient *radarr.CachedClient
+
+// RadarrCommandHandler defines the signature of a Radarr command, called with the Radarr client
+type RadarrCommandHandler func(s *discordgo.Session, m *discordgo.MessageCreate, client radarr.Service, args []string)
+
+// radarrHandler adapts a Radarr command to a CommandHandler, telling the channel when Radarr is not configured
+func radarrHandler(handler RadarrCommandHandler) CommandHandler {
+	return func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+		if radarrClient == nil {
+			s.ChannelMessageSend(m.ChannelID, "Radarr is not configured, check the config and credentials files.")
+			return
+		}
+		handler(s, m, radarrClient, args)
+	}
+}
+
+// loadRadarr creates the Radarr client when radarr_instance is set in the config file
+func loadRadarr(config auth.Config, creds auth.Auth) {
+	if config.RadarrInstance == "" {
+		return
+	}
+
+	client := radarr.NewClient(radarr.Config{
+		BaseURL: radarr.BaseURL(config.RadarrInstance, config.RadarrPort),
+		APIKey:  creds.RadarrApiToken,
+		Timeout: secondsOrDefault(config.RadarrTimeoutSeconds, defaultRadarrTimeout),
+		Retry: api.RetryPolicy{
+			Retries: config.RadarrRetries,
+			Backoff: time.Second,
+		},
+	})
+	radarrClient = radarr.NewCachedClient(client, minutesOrDefault(config.RadarrCacheMinutes, defaultRadarrCacheInterval))
+}
+
+// handleRadarrMovieLookup responds to the !radarrlookup command
+func handleRadarrMovieLookup(s *discordgo.Session, m *discordgo.MessageCreate, client radarr.Service, args []string) {
+	log.Println("Radarr Lookup arguments:", args)
+
+	// Check if argument is provided
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "radarrlookup")
+		return
+	}
+
+	// Call the Radarr API
+	query := strings.Join(args, " ")
+	results, err := client.Lookup(query)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Radarr", "looking up movies on Radarr", err))
+		return
+	}
+
+	if len(results) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "No movies found.")
+		return
+	}
+
+	// Rank the results against the query, keeping those that only match on TMDb data Radarr does not return
+	keys := make([][]string, len(results))
+	for index, movie := range results {
+		keys[index] = radarr.SearchKeys(movie)
+	}
+
+	var message string
+	for _, match := range fuzzy.Rank(query, keys, searchLimit, 0) {
+		movie := results[match.Index]
+		line := fmt.Sprintf("- %s (%d) · %s", movie.Title, movie.Year, matchScore(match.Score))
+		if movie.ID != 0 {
+			line += " · already in Radarr"
+		}
+		message += line + "\n"
+	}
+	if len(results) > searchLimit {
+		message += fmt.Sprintf("...and %d more\n", len(results)-searchLimit)
+	}
+
+	// Send the message in chunks if it's too long
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// handleRadarrLocalMovieSearch responds to the !radarrls command
+func handleRadarrLocalMovieSearch(s *discordgo.Session, m *discordgo.MessageCreate, client radarr.Service, args []string) {
+	log.Println("Radarr API Local search arguments:", args)
+
+	// Check if argument is provided
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "radarrls")
+		return
+	}
+
+	// Search the movies of the local Radarr instance
+	matchingMovies, err := client.SearchMovies(strings.Join(args, " "), searchLimit)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Radarr", "fetching movies from Radarr", err))
+		return
+	}
+
+	if len(matchingMovies) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "No matching movies found.")
+		return
+	}
+
+	// Build the response message
+	var message string
+	for _, match := range matchingMovies {
+		movie := match.Movie
+		file := "missing"
+		if movie.HasFile {
+			file = "downloaded"
+		}
+		message += fmt.Sprintf(
+			"**%s** (ID: %d) · %s\n- Year: %d\n- Status: %s\n- File: %s\n- Genres: %s\n\n",
+			movie.Title, movie.ID, matchScore(match.Score), movie.Year, movie.Status, file, strings.Join(movie.Genres, ", "),
+		)
+	}
+
+	// Send the message in chunks if it's too long
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// autocompleteRadarrLocalMovies offers the local Radarr movies matching the typed value
+func autocompleteRadarrLocalMovies(s *discordgo.Session, i *discordgo.InteractionCreate, value string) []*discordgo.ApplicationCommandOptionChoice {
+	if radarrClient == nil || strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	// Discord accepts at most 25 choices
+	matches, err := radarrClient.SearchMovies(value, 25)
+	if err != nil {
+		log.Println("Error fetching movies from Radarr:", err)
+		return nil
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, match := range matches {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  match.Movie.Title,
+			Value: match.Movie.Title,
+		})
+	}
+	return choices
+}
+
+// movieRelease is a cinema, digital or physical release of a movie, listed by !radarrcal
+type movieRelease struct {
+	date  time.Time
+	kind  string
+	movie radarr.Movie
+}
+
+// handleRadarrCalendar responds to the !radarrcal command with the movies released in the coming days
+func handleRadarrCalendar(s *discordgo.Session, m *discordgo.MessageCreate, client radarr.Service, args []string) {
+	days := defaultCalendarDays
+	if len(args) > 0 {
+		var err error
+		days, err = strconv.Atoi(args[0])
+		if err != nil || days < 1 || days > maxCalendarDays {
+			sendUsage(s, m.ChannelID, "radarrcal")
+			return
+		}
+	}
+
+	// Release dates have no time of day, so today is taken in the configured timezone and compared as a UTC date
+	now := time.Now().In(botLocation)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, days)
+
+	movies, err := client.Calendar(start, end)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Radarr", "fetching the calendar from Radarr", err))
+		return
+	}
+
+	// A movie can have several releases in the period, each is listed on its own day
+	var releases []movieRelease
+	for _, movie := range movies {
+		for _, release := range []struct {
+			date *time.Time
+			kind string
+		}{
+			{movie.InCinemas, "🎬 In cinemas"},
+			{movie.DigitalRelease, "📺 Digital"},
+			{movie.PhysicalRelease, "💿 Physical"},
+		} {
+			if release.date != nil && !release.date.Before(start) && release.date.Before(end) {
+				releases = append(releases, movieRelease{date: release.date.UTC(), kind: release.kind, movie: movie})
+			}
+		}
+	}
+
+	if len(releases) == 0 {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("No movies released in the next %d days.", days))
+		return
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].date.Before(releases[j].date)
+	})
+
+	// Group the releases by day, one line per release
+	var message string
+	var currentDay string
+	for _, release := range releases {
+		if day := release.date.Format("Monday 2 January"); day != currentDay {
+			currentDay = day
+			message += fmt.Sprintf("\n**%s**\n", day)
+		}
+
+		status := "⏳"
+		if release.movie.HasFile {
+			status = "✅"
+		}
+
+		message += fmt.Sprintf("%s %s · %s (%d)\n", status, release.kind, release.movie.Title, release.movie.Year)
+	}
+
+	sendMessageChunks(s, m.ChannelID, strings.TrimPrefix(message, "\n"))
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"fmt"
+	"main/radarr"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// radarrAddKind sets up the add menus for movies
+var radarrAddKind = &arrAddKind{
+	app:           radarrApp,
+	title:         "Movie",
+	singular:      "movie",
+	plural:        "movies",
+	optionLabel:   "Minimum availability",
+	options:       radarr.MinimumAvailabilities,
+	defaultOption: "released",
+	searchLabel:   "Add and search",
+}
+
+// radarrAdder adds the movie picked in the !radarradd menus
+type radarrAdder struct {
+	client  radarr.Service
+	results []radarr.Movie // lookup results not yet in Radarr
+}
+
+// handleRadarrAdd responds to the !radarradd command with menus to pick the movie and its options
+func handleRadarrAdd(s *discordgo.Session, m *discordgo.MessageCreate, client radarr.Service, args []string) {
+	handleArrAdd(s, m, radarrAddKind, &radarrAdder{client: client}, args)
+}
+
+// Lookup looks the movie up on TMDb through Radarr, keeping the ones not in Radarr yet
+func (a *radarrAdder) Lookup(term string, limit int) ([]arrAddResult, int, error) {
+	lookup, err := a.client.Lookup(term)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var results []arrAddResult
+	alreadyAdded := 0
+	for _, movie := range lookup {
+		if movie.ID != 0 {
+			alreadyAdded++
+			continue
+		}
+		if len(a.results) == limit {
+			continue
+		}
+
+		description := fmt.Sprintf("TMDb %d", movie.TmdbID)
+		if movie.Studio != "" {
+			description = movie.Studio + " · " + description
+		}
+		a.results = append(a.results, movie)
+		results = append(results, arrAddResult{Title: movie.Title, Year: movie.Year, Description: description})
+	}
+	return results, alreadyAdded, nil
+}
+
+// QualityProfiles returns the Radarr quality profiles
+func (a *radarrAdder) QualityProfiles() ([]arrProfile, error) {
+	profiles, err := a.client.QualityProfiles()
+	var choices []arrProfile
+	for _, profile := range profiles {
+		choices = append(choices, arrProfile{ID: profile.ID, Name: profile.Name})
+	}
+	return choices, err
+}
+
+// RootFolders returns the Radarr root folders
+func (a *radarrAdder) RootFolders() ([]arrRootFolder, error) {
+	folders, err := a.client.RootFolders()
+	var choices []arrRootFolder
+	for _, folder := range folders {
+		choices = append(choices, arrRootFolder{Path: folder.Path, FreeSpace: folder.FreeSpace})
+	}
+	return choices, err
+}
+
+// Add posts the chosen movie to Radarr with the minimum availability picked in the last menu
+func (a *radarrAdder) Add(choices arrAddChoices, search bool) (string, error) {
+	movie := a.results[choices.Result]
+
+	added, err := a.client.AddMovie(radarr.NewMovie{
+		Title:               movie.Title,
+		TitleSlug:           movie.TitleSlug,
+		TmdbID:              movie.TmdbID,
+		Year:                movie.Year,
+		Images:              movie.Images,
+		QualityProfileID:    choices.ProfileID,
+		RootFolderPath:      choices.RootPath,
+		Monitored:           true,
+		MinimumAvailability: choices.Option,
+		AddOptions: radarr.AddOptions{
+			Monitor:        "movieOnly",
+			SearchForMovie: search,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	content := fmt.Sprintf("✅ Added **%s** (%d) to Radarr (ID: %d), minimum availability: %s.", added.Title, added.Year, added.ID, choices.Option)
+	if search {
+		content += " Searching for the movie."
+	}
+	return content, nil
+}
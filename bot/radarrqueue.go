@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"fmt"
+	"main/radarr"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// radarrQueue is the Radarr download queue shown by !radarrqueue
+type radarrQueue struct {
+	client radarr.Service
+}
+
+// handleRadarrQueue responds to the !radarrqueue command, listing the queue or removing an item from it
+func handleRadarrQueue(s *discordgo.Session, m *discordgo.MessageCreate, client radarr.Service, args []string) {
+	handleArrQueue(s, m, radarrApp, radarrQueue{client}, args)
+}
+
+// Items returns the first count items of the queue with their movie names
+func (q radarrQueue) Items(count int) ([]arrQueueItem, int, error) {
+	queue, err := q.client.Queue(1, count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []arrQueueItem
+	for _, record := range queue.Records {
+		// Prefer the movie name over the release title
+		title := record.Title
+		if record.Movie != nil {
+			title = fmt.Sprintf("%s (%d)", record.Movie.Title, record.Movie.Year)
+		}
+
+		item := arrQueueItem{
+			ID:                    record.ID,
+			Title:                 title,
+			Progress:              record.Progress(),
+			Size:                  int64(record.Size),
+			TimeLeft:              record.TimeLeft,
+			DownloadClient:        record.DownloadClient,
+			Status:                record.Status,
+			TrackedDownloadStatus: record.TrackedDownloadStatus,
+			ErrorMessage:          record.ErrorMessage,
+		}
+		for _, status := range record.StatusMessages {
+			item.Warnings = append(item.Warnings, status.Messages...)
+		}
+		items = append(items, item)
+	}
+	return items, queue.TotalRecords, nil
+}
+
+// Remove removes an item from the queue and the download client
+func (q radarrQueue) Remove(id int, blocklist bool) error {
+	return q.client.RemoveFromQueue(id, blocklist)
+}
@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"main/auth"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate limits used when neither the command nor the config file sets one
+const (
+	defaultBurst         = 5
+	defaultRefillSeconds = 3
+	defaultMaxConcurrent = 4
+)
+
+// bucketSweepInterval is how often Allow drops the buckets that have refilled, a full bucket is the same as none
+const bucketSweepInterval = time.Minute
+
+// commandLimiter is the rate limiter created by Init from the config file
+var commandLimiter = NewRateLimiter(auth.RateLimitConfig{})
+
+// tokenBucket tracks the tokens left for one user and command
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket is back to its burst size
+}
+
+// RateLimiter throttles commands with a token bucket per user and command,
+// and caps the number of commands running at the same time.
+type RateLimiter struct {
+	mu        sync.Mutex
+	config    auth.RateLimitConfig
+	buckets   map[string]*tokenBucket // keyed by user ID and command name
+	lastSweep time.Time               // when the refilled buckets were last dropped
+	slots     chan struct{}           // one entry per running command
+}
+
+// NewRateLimiter creates a rate limiter, filling in the defaults for unset values
+func NewRateLimiter(config auth.RateLimitConfig) *RateLimiter {
+	if config.Default.Burst <= 0 {
+		config.Default.Burst = defaultBurst
+	}
+	if config.Default.RefillSeconds <= 0 {
+		config.Default.RefillSeconds = defaultRefillSeconds
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = defaultMaxConcurrent
+	}
+
+	return &RateLimiter{
+		config:    config,
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+		slots:     make(chan struct{}, config.MaxConcurrent),
+	}
+}
+
+// rule returns the limit for cmd: the config file override, then the command's own limit, then the default
+func (l *RateLimiter) rule(cmd *Command) auth.RateLimitRule {
+	rule, ok := l.config.Commands[cmd.Name]
+	if !ok {
+		rule = cmd.RateLimit
+	}
+
+	if rule.Burst <= 0 {
+		rule.Burst = l.config.Default.Burst
+	}
+	if rule.RefillSeconds <= 0 {
+		rule.RefillSeconds = l.config.Default.RefillSeconds
+	}
+	return rule
+}
+
+// Allow takes a token from the bucket of the user and command.
+// When the bucket is empty it returns false and how long until the next token is available.
+func (l *RateLimiter) Allow(userID string, cmd *Command) (bool, time.Duration) {
+	rule := l.rule(cmd)
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= bucketSweepInterval {
+		l.sweep(now)
+	}
+
+	key := userID + ":" + cmd.Name
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(rule.Burst), last: now}
+		l.buckets[key] = bucket
+	}
+
+	// Refill the tokens earned since the last call, up to the burst size
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.tokens = math.Min(float64(rule.Burst), bucket.tokens+elapsed/rule.RefillSeconds)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := (1 - bucket.tokens) * rule.RefillSeconds
+		return false, time.Duration(wait * float64(time.Second))
+	}
+
+	bucket.tokens--
+	bucket.full = now.Add(time.Duration((float64(rule.Burst) - bucket.tokens) * rule.RefillSeconds * float64(time.Second)))
+	return true, 0
+}
+
+// sweep drops the buckets that have refilled by now so the map does not grow with every user seen.
+// The caller must hold l.mu.
+func (l *RateLimiter) sweep(now time.Time) {
+	for key, bucket := range l.buckets {
+		if !bucket.full.After(now) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Acquire reserves one of the concurrent command slots, returning false if they are all in use
+func (l *RateLimiter) Acquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees a slot reserved by Acquire
+func (l *RateLimiter) Release() {
+	<-l.slots
+}
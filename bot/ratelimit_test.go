@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"main/auth"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := NewRateLimiter(auth.RateLimitConfig{})
+	cmd := &Command{Name: "sonarrls", RateLimit: auth.RateLimitRule{Burst: 2, RefillSeconds: 10}}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		if ok, _ := limiter.Allow("user", cmd); !ok {
+			t.Fatalf("attempt %d refused within the burst", attempt)
+		}
+	}
+	ok, retryAfter := limiter.Allow("user", cmd)
+	if ok {
+		t.Fatal("attempt 3 allowed past the burst")
+	}
+	if retryAfter <= 9*time.Second || retryAfter > 10*time.Second {
+		t.Errorf("retry after %v, want about 10s", retryAfter)
+	}
+
+	// Other users and commands have their own buckets
+	if ok, _ := limiter.Allow("other", cmd); !ok {
+		t.Error("another user was refused")
+	}
+	if ok, _ := limiter.Allow("user", &Command{Name: "ping"}); !ok {
+		t.Error("another command was refused")
+	}
+}
+
+func TestRateLimiterDropsRefilledBuckets(t *testing.T) {
+	limiter := NewRateLimiter(auth.RateLimitConfig{})
+	cmd := &Command{Name: "sonarrls", RateLimit: auth.RateLimitRule{Burst: 2, RefillSeconds: 10}}
+
+	limiter.Allow("refilled", cmd)
+	limiter.Allow("refilling", cmd)
+
+	// Move the clock of the buckets back: the first one has refilled since, the second not yet
+	limiter.buckets["refilled:sonarrls"].full = time.Now().Add(-time.Second)
+	limiter.buckets["refilling:sonarrls"].full = time.Now().Add(time.Hour)
+	limiter.lastSweep = time.Now().Add(-bucketSweepInterval)
+
+	limiter.Allow("new", cmd)
+	if _, ok := limiter.buckets["refilled:sonarrls"]; ok {
+		t.Error("the refilled bucket was kept")
+	}
+	if _, ok := limiter.buckets["refilling:sonarrls"]; !ok {
+		t.Error("the bucket still refilling was dropped")
+	}
+	if len(limiter.buckets) != 2 {
+		t.Errorf("%d buckets, want 2", len(limiter.buckets))
+	}
+}
+
+func TestAdmitCommandKeepsTokenWhenBusy(t *testing.T) {
+	previous := commandLimiter
+	t.Cleanup(func() { commandLimiter = previous })
+	commandLimiter = NewRateLimiter(auth.RateLimitConfig{MaxConcurrent: 1})
+	cmd := &Command{Name: "sonarrls", RateLimit: auth.RateLimitRule{Burst: 1, RefillSeconds: 60}}
+
+	// Another command holds the only slot, the user is told the bot is busy several times
+	if !commandLimiter.Acquire() {
+		t.Fatal("Acquire() = false on an idle limiter")
+	}
+	for attempt := 1; attempt <= 3; attempt++ {
+		if _, err := admitCommand(testMessage(), cmd); err == nil || !strings.Contains(err.Error(), "busy") {
+			t.Fatalf("attempt %d error = %v, want busy", attempt, err)
+		}
+	}
+	commandLimiter.Release()
+
+	// The single token is still there once the slot is free
+	release, err := admitCommand(testMessage(), cmd)
+	if err != nil {
+		t.Fatalf("admitCommand() after the busy replies error = %v, want the command admitted", err)
+	}
+	release()
+
+	// A rate limited attempt gives its slot back
+	if _, err := admitCommand(testMessage(), cmd); err == nil || !strings.Contains(err.Error(), "Slow down") {
+		t.Fatalf("admitCommand() past the burst error = %v, want slow down", err)
+	}
+	if !commandLimiter.Acquire() {
+		t.Error("the rate limited attempt kept the slot")
+	}
+}
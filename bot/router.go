@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"fmt"
+	"main/fuzzy"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Router dispatches messages to the registered commands by exact match on the first token
+type Router struct {
+	Prefix   string
+	commands []*Command          // registered commands in registration order
+	names    map[string]*Command // command names and aliases, lowercased
+}
+
+// NewRouter creates an empty router for commands starting with prefix
+func NewRouter(prefix string) *Router {
+	return &Router{
+		Prefix: prefix,
+		names:  make(map[string]*Command),
+	}
+}
+
+// Register adds a command to the router, failing if its name or an alias is already taken
+func (r *Router) Register(cmd *Command) error {
+	names := append([]string{cmd.Name}, cmd.Aliases...)
+	for _, name := range names {
+		if _, exists := r.names[strings.ToLower(name)]; exists {
+			return fmt.Errorf("command name %q is already registered", name)
+		}
+	}
+
+	for _, name := range names {
+		r.names[strings.ToLower(name)] = cmd
+	}
+	r.commands = append(r.commands, cmd)
+	return nil
+}
+
+// Lookup returns the command registered under name or one of its aliases (without prefix)
+func (r *Router) Lookup(name string) (*Command, bool) {
+	cmd, ok := r.names[strings.ToLower(name)]
+	return cmd, ok
+}
+
+// Commands returns the registered commands sorted by name
+func (r *Router) Commands() []*Command {
+	commands := make([]*Command, len(r.commands))
+	copy(commands, r.commands)
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// Parse splits a message into the command name (without prefix) and its arguments.
+// ok is false when the message does not start with the prefix or has no command name.
+func (r *Router) Parse(content string) (name string, args []string, ok bool) {
+	tokens := tokenize(content)
+	if len(tokens) == 0 || !strings.HasPrefix(tokens[0], r.Prefix) {
+		return "", nil, false
+	}
+
+	name = strings.ToLower(strings.TrimPrefix(tokens[0], r.Prefix))
+	if name == "" {
+		return "", nil, false
+	}
+
+	return name, tokens[1:], true
+}
+
+// Suggest returns the registered names and aliases closest to name by edit distance, best first
+func (r *Router) Suggest(name string) []string {
+	name = strings.ToLower(name)
+
+	// Allow roughly one typo per three characters, with a minimum of two
+	maxDistance := len(name) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for known := range r.names {
+		if distance := fuzzy.Levenshtein(name, known); distance <= maxDistance {
+			candidates = append(candidates, candidate{known, distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var suggestions []string
+	for _, c := range candidates {
+		suggestions = append(suggestions, c.name)
+		if len(suggestions) == 3 {
+			break
+		}
+	}
+	return suggestions
+}
+
+// tokenize splits a message on whitespace, keeping "double quoted" text together as a single token
+func tokenize(content string) []string {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	hasToken := false
+
+	for _, r := range content {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasToken = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+
+	if hasToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// splitFlags separates "--name value" and "--name=value" flags from the other arguments. Names listed in
+// valueFlags take the next argument as their value, other flags are set to "true" unless given "=value".
+// Flag names are matched ignoring case.
+func splitFlags(args []string, valueFlags ...string) (flags map[string]string, rest []string) {
+	flags = make(map[string]string)
+	for index := 0; index < len(args); index++ {
+		name, isFlag := strings.CutPrefix(args[index], "--")
+		if !isFlag || name == "" || strings.HasPrefix(name, "=") {
+			rest = append(rest, args[index])
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(name, "=")
+		name = strings.ToLower(name)
+		if hasValue {
+			flags[name] = value
+		} else if slices.Contains(valueFlags, name) && index+1 < len(args) {
+			index++
+			flags[name] = args[index]
+		} else {
+			flags[name] = "true"
+		}
+	}
+	return flags, rest
+}
+
+// takeFlag removes the "--name value" or "--name=value" flag from the arguments and returns its value, empty
+// when it is not given. Unlike splitFlags the other flags are left in place for the command to parse.
+func takeFlag(args []string, name string) (value string, rest []string) {
+	for index := 0; index < len(args); index++ {
+		flag, isFlag := strings.CutPrefix(args[index], "--")
+		if isFlag {
+			if flagName, flagValue, hasValue := strings.Cut(flag, "="); hasValue && strings.EqualFold(flagName, name) {
+				value = flagValue
+				continue
+			}
+			if strings.EqualFold(flag, name) && index+1 < len(args) {
+				value = args[index+1]
+				index++
+				continue
+			}
+		}
+		rest = append(rest, args[index])
+	}
+	return value, rest
+}
@@ -0,0 +1,151 @@
+package bot
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"!sonarrls", []string{"!sonarrls"}},
+		{"  !sonarrls   breaking   bad ", []string{"!sonarrls", "breaking", "bad"}},
+		{`!sonarrdelete "Breaking Bad" --files`, []string{"!sonarrdelete", "Breaking Bad", "--files"}},
+		{`!search "the office"us`, []string{"!search", "the officeus"}},
+		{`!search ""`, []string{"!search", ""}},
+		{`!search "unterminated quote --cat tv`, []string{"!search", "unterminated quote --cat tv"}},
+		{"!sonarrls\tbreaking\nbad", []string{"!sonarrls", "breaking", "bad"}},
+		{"", nil},
+	}
+
+	for _, test := range tests {
+		if got := tokenize(test.content); !slices.Equal(got, test.want) {
+			t.Errorf("tokenize(%q) = %q, want %q", test.content, got, test.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	router := NewRouter("!")
+	tests := []struct {
+		content  string
+		wantName string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{"!SonarrLS Breaking Bad", "sonarrls", []string{"Breaking", "Bad"}, true},
+		{"!ping", "ping", []string{}, true},
+		{"sonarrls", "", nil, false},
+		{"! ping", "", nil, false},
+		{"", "", nil, false},
+	}
+
+	for _, test := range tests {
+		name, args, ok := router.Parse(test.content)
+		if name != test.wantName || !slices.Equal(args, test.wantArgs) || ok != test.wantOK {
+			t.Errorf("Parse(%q) = %q, %q, %v, want %q, %q, %v", test.content, name, args, ok, test.wantName, test.wantArgs, test.wantOK)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	router := NewRouter("!")
+	for _, command := range []*Command{
+		{Name: "sonarrls", Aliases: []string{"series"}},
+		{Name: "sonarrlookup"},
+		{Name: "sonarradd"},
+		{Name: "sonarrcalendar", Aliases: []string{"sonarrcal"}},
+		{Name: "radarrls"},
+		{Name: "ping"},
+	} {
+		if err := router.Register(command); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"sonarlS", []string{"sonarrls"}},
+		{"sonarrad", []string{"sonarradd", "sonarrcal", "sonarrls"}},
+		{"serie", []string{"series"}},
+		{"pong", []string{"ping"}},
+		// 8 letters allow 2 edits, "sonarrls" is 3 away
+		{"snrls", nil},
+		// 11 letters allow 3 edits
+		{"sonarcalndr", []string{"sonarrcalendar"}},
+		{"snrcalndr", nil},
+		{"sonarrcalnedar", []string{"sonarrcalendar"}},
+		// 6 letters allow 2 edits, "sonarradd" is 3 away
+		{"sonarr", []string{"sonarrls"}},
+		{"unknowncommand", nil},
+	}
+
+	for _, test := range tests {
+		if got := router.Suggest(test.name); !slices.Equal(got, test.want) {
+			t.Errorf("Suggest(%q) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSplitFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantFlags map[string]string
+		wantRest  []string
+	}{
+		{"no flags", []string{"breaking", "bad"}, map[string]string{}, []string{"breaking", "bad"}},
+		{"switch", []string{"breaking", "--FILES", "bad"}, map[string]string{"files": "true"}, []string{"breaking", "bad"}},
+		{"value", []string{"--season", "2", "severance"}, map[string]string{"season": "2"}, []string{"severance"}},
+		{"equals value", []string{"--season=2", "severance"}, map[string]string{"season": "2"}, []string{"severance"}},
+		{"equals value on a switch", []string{"--files=false"}, map[string]string{"files": "false"}, nil},
+		{"empty equals value", []string{"--season=", "severance"}, map[string]string{"season": ""}, []string{"severance"}},
+		{"value missing", []string{"severance", "--season"}, map[string]string{"season": "true"}, []string{"severance"}},
+		{"value looking like a flag", []string{"--season", "--files"}, map[string]string{"season": "--files"}, nil},
+		{"bare dashes", []string{"--", "--=2", "-x"}, map[string]string{}, []string{"--", "--=2", "-x"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags, rest := splitFlags(test.args, "season")
+			if !maps.Equal(flags, test.wantFlags) {
+				t.Errorf("flags = %v, want %v", flags, test.wantFlags)
+			}
+			if !slices.Equal(rest, test.wantRest) {
+				t.Errorf("rest = %q, want %q", rest, test.wantRest)
+			}
+		})
+	}
+}
+
+func TestTakeFlag(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantValue string
+		wantRest  []string
+	}{
+		{"absent", []string{"severance", "--files"}, "", []string{"severance", "--files"}},
+		{"value", []string{"--instance", "4k", "severance", "--files"}, "4k", []string{"severance", "--files"}},
+		{"equals value", []string{"severance", "--Instance=4k", "--files"}, "4k", []string{"severance", "--files"}},
+		{"value missing", []string{"severance", "--instance"}, "", []string{"severance", "--instance"}},
+		{"other flag with a value", []string{"--season=2", "severance"}, "", []string{"--season=2", "severance"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, rest := takeFlag(test.args, "instance")
+			if value != test.wantValue {
+				t.Errorf("value = %q, want %q", value, test.wantValue)
+			}
+			if !slices.Equal(rest, test.wantRest) {
+				t.Errorf("rest = %q, want %q", rest, test.wantRest)
+			}
+		})
+	}
+}
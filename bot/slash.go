@@ -0,0 +1,282 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/auth"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// AutocompleteHandler returns the choices offered for the focused option of a slash command
+type AutocompleteHandler func(s *discordgo.Session, i *discordgo.InteractionCreate, value string) []*discordgo.ApplicationCommandOptionChoice
+
+// Discord limits on application commands, a single command over a limit makes Discord reject the whole set
+const (
+	maxSlashDescription = 100
+	maxSlashOptions     = 25
+	maxSlashChoices     = 25
+	maxSlashChoiceName  = 100
+)
+
+// slashNamePattern matches the names Discord accepts for commands and options: 1 to 32 lowercase letters,
+// digits, dashes and underscores
+var slashNamePattern = regexp.MustCompile(`^[-_\p{Ll}\p{Lo}\p{N}]{1,32}$`)
+
+// slashCommands builds the application command definitions registered with Discord at startup.
+// Each slash command runs the registered command of the same name.
+func slashCommands() ([]*discordgo.ApplicationCommand, error) {
+	var commands []*discordgo.ApplicationCommand
+	for _, cmd := range Commands.Commands() {
+		command := &discordgo.ApplicationCommand{
+			Name:        cmd.Name,
+			Description: cmd.Description,
+			Options:     cmd.Options,
+		}
+		if err := validateSlashCommand(command); err != nil {
+			return nil, fmt.Errorf("slash command %s: %w", cmd.Name, err)
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// validateSlashCommand checks a command and its options against the Discord limits
+func validateSlashCommand(command *discordgo.ApplicationCommand) error {
+	if !slashNamePattern.MatchString(command.Name) {
+		return fmt.Errorf("name %q is not 1 to 32 lowercase letters, digits, dashes or underscores", command.Name)
+	}
+	if length := utf8.RuneCountInString(command.Description); length == 0 || length > maxSlashDescription {
+		return fmt.Errorf("description is %d characters, Discord allows 1 to %d", length, maxSlashDescription)
+	}
+	return validateSlashOptions(command.Options)
+}
+
+// validateSlashOptions checks options, their choices and their subcommand options against the Discord limits
+func validateSlashOptions(options []*discordgo.ApplicationCommandOption) error {
+	if len(options) > maxSlashOptions {
+		return fmt.Errorf("%d options, Discord allows %d", len(options), maxSlashOptions)
+	}
+
+	names := make(map[string]bool)
+	optional := false
+	for _, option := range options {
+		if !slashNamePattern.MatchString(option.Name) {
+			return fmt.Errorf("option name %q is not 1 to 32 lowercase letters, digits, dashes or underscores", option.Name)
+		}
+		if names[option.Name] {
+			return fmt.Errorf("option %s is defined twice", option.Name)
+		}
+		names[option.Name] = true
+
+		// Discord wants the required options first
+		if option.Required && optional {
+			return fmt.Errorf("required option %s comes after an optional one", option.Name)
+		}
+		optional = optional || !option.Required
+
+		if length := utf8.RuneCountInString(option.Description); length == 0 || length > maxSlashDescription {
+			return fmt.Errorf("option %s description is %d characters, Discord allows 1 to %d", option.Name, length, maxSlashDescription)
+		}
+		if len(option.Choices) > maxSlashChoices {
+			return fmt.Errorf("option %s has %d choices, Discord allows %d", option.Name, len(option.Choices), maxSlashChoices)
+		}
+		for _, choice := range option.Choices {
+			if length := utf8.RuneCountInString(choice.Name); length == 0 || length > maxSlashChoiceName {
+				return fmt.Errorf("option %s choice %q is %d characters, Discord allows 1 to %d", option.Name, choice.Name, length, maxSlashChoiceName)
+			}
+		}
+		if err := validateSlashOptions(option.Options); err != nil {
+			return fmt.Errorf("option %s: %w", option.Name, err)
+		}
+	}
+	return nil
+}
+
+// registerSlashCommands overwrites the bot's application commands with the registered commands.
+// Commands are registered to the configured guild when set (instant update), otherwise globally.
+// Nothing is registered when the config file cannot be read, as the guild would be unknown and the
+// global commands would be overwritten instead.
+func registerSlashCommands(s *discordgo.Session) {
+	config, err := auth.LoadConfig()
+	if err != nil {
+		log.Println("Error loading config file, slash commands not registered:", err)
+		return
+	}
+
+	commands, err := slashCommands()
+	if err != nil {
+		log.Fatalf("Error building slash commands: %v", err)
+	}
+
+	registered, err := s.ApplicationCommandBulkOverwrite(s.State.User.ID, config.DiscordGuildID, commands)
+	if err != nil {
+		log.Println("Error registering slash commands:", err)
+		return
+	}
+
+	log.Printf("Registered %d slash commands", len(registered))
+}
+
+// interactionHandler processes incoming slash commands, autocomplete requests and message components
+func interactionHandler(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		handleSlashCommand(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		handleAutocomplete(s, i)
+	case discordgo.InteractionMessageComponent:
+		handleComponent(s, i)
+	}
+}
+
+// handleSlashCommand runs the legacy command handler matching the slash command name
+func handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+
+	cmd, ok := Commands.Lookup(data.Name)
+	if !ok {
+		log.Println("Unknown slash command:", data.Name)
+		return
+	}
+
+	args := flattenOptions(data.Options, cmd.Flags)
+	content := strings.TrimSpace(Commands.Prefix + cmd.Name + " " + strings.Join(args, " "))
+	m := interactionMessage(i, content)
+
+	// Check the user is allowed to run the command now, the denial is only shown to them
+	release, err := admitCommand(m, cmd)
+	if err != nil {
+		respondEphemeral(s, i, err.Error())
+		return
+	}
+	defer release()
+
+	// Acknowledge the interaction, the handler replies in the channel as it does for "!" commands
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Running `%s`", content),
+		},
+	})
+	if err != nil {
+		log.Println("Error responding to interaction:", err)
+	}
+
+	cmd.Handler(s, m, args)
+}
+
+// respondEphemeral answers an interaction with a message only visible to the user who triggered it
+func respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Println("Error responding to interaction:", err)
+	}
+}
+
+// handleAutocomplete responds with the choices for the focused option
+func handleAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	if cmd, ok := Commands.Lookup(data.Name); ok && cmd.Autocomplete != nil {
+		if focused := focusedOption(data.Options); focused != nil {
+			choices = cmd.Autocomplete(s, i, fmt.Sprint(focused.Value))
+		}
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{
+			Choices: choices,
+		},
+	})
+	if err != nil {
+		log.Println("Error responding to autocomplete:", err)
+	}
+}
+
+// interactionMessage builds the MessageCreate event the legacy handlers expect from an interaction
+func interactionMessage(i *discordgo.InteractionCreate, content string) *discordgo.MessageCreate {
+	author := interactionUser(i)
+
+	return &discordgo.MessageCreate{
+		Message: &discordgo.Message{
+			ID:        i.ID,
+			ChannelID: i.ChannelID,
+			GuildID:   i.GuildID,
+			Author:    author,
+			Member:    i.Member,
+			Content:   content,
+		},
+	}
+}
+
+// flattenOptions converts slash command options into the argument list of the "!" commands.
+// Subcommands contribute their name followed by their own options, string values are split on whitespace.
+// Options named in flags are passed as "--name value", or just "--name" for a true boolean.
+func flattenOptions(options []*discordgo.ApplicationCommandInteractionDataOption, flags []string) []string {
+	var args, flagArgs []string
+	for _, option := range options {
+		if slices.Contains(flags, option.Name) && option.Type != discordgo.ApplicationCommandOptionSubCommand {
+			switch option.Type {
+			case discordgo.ApplicationCommandOptionBoolean:
+				if option.BoolValue() {
+					flagArgs = append(flagArgs, "--"+option.Name)
+				}
+			default:
+				flagArgs = append(flagArgs, "--"+option.Name, fmt.Sprint(option.Value))
+			}
+			continue
+		}
+
+		switch option.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			args = append(args, option.Name)
+			args = append(args, flattenOptions(option.Options, flags)...)
+		case discordgo.ApplicationCommandOptionString:
+			args = append(args, strings.Fields(option.StringValue())...)
+		case discordgo.ApplicationCommandOptionInteger:
+			args = append(args, fmt.Sprint(option.IntValue()))
+		default:
+			args = append(args, fmt.Sprint(option.Value))
+		}
+	}
+	return append(args, flagArgs...)
+}
+
+// focusedOption returns the option the user is currently typing in, searching subcommands as well
+func focusedOption(options []*discordgo.ApplicationCommandInteractionDataOption) *discordgo.ApplicationCommandInteractionDataOption {
+	for _, option := range options {
+		if option.Focused {
+			return option
+		}
+		if focused := focusedOption(option.Options); focused != nil {
+			return focused
+		}
+	}
+	return nil
+}
+
+// optionValue returns the string value of the named option, searching subcommands as well, empty when it is not set
+func optionValue(options []*discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, option := range options {
+		if option.Name == name && option.Type == discordgo.ApplicationCommandOptionString {
+			return option.StringValue()
+		}
+		if value := optionValue(option.Options, name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSlashCommands(t *testing.T) {
+	commands, err := slashCommands()
+	if err != nil {
+		t.Fatalf("slashCommands() error = %v", err)
+	}
+	if len(commands) != len(commandList()) {
+		t.Errorf("%d slash commands, want one per command (%d)", len(commands), len(commandList()))
+	}
+}
+
+func TestValidateSlashCommand(t *testing.T) {
+	option := func(name string, required bool) *discordgo.ApplicationCommandOption {
+		return &discordgo.ApplicationCommandOption{Type: discordgo.ApplicationCommandOptionString, Name: name, Description: "An option", Required: required}
+	}
+	choices := func(count int, name string) []*discordgo.ApplicationCommandOptionChoice {
+		var choices []*discordgo.ApplicationCommandOptionChoice
+		for range count {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+		}
+		return choices
+	}
+	withChoices := func(option *discordgo.ApplicationCommandOption, choices []*discordgo.ApplicationCommandOptionChoice) *discordgo.ApplicationCommandOption {
+		option.Choices = choices
+		return option
+	}
+
+	tests := []struct {
+		name    string
+		command discordgo.ApplicationCommand
+		wantErr string // part of the error, empty when valid
+	}{
+		{"valid", discordgo.ApplicationCommand{Name: "sonarrls", Description: "List series",
+			Options: []*discordgo.ApplicationCommandOption{option("series", true), option("instance", false)}}, ""},
+		{"uppercase name", discordgo.ApplicationCommand{Name: "SonarrLS", Description: "List series"}, "name"},
+		{"name with a space", discordgo.ApplicationCommand{Name: "sonarr ls", Description: "List series"}, "name"},
+		{"no description", discordgo.ApplicationCommand{Name: "sonarrls"}, "description is 0 characters"},
+		{"long description", discordgo.ApplicationCommand{Name: "sonarrls", Description: strings.Repeat("x", 101)}, "description is 101 characters"},
+		{"bad option name", discordgo.ApplicationCommand{Name: "sonarrls", Description: "List series",
+			Options: []*discordgo.ApplicationCommandOption{option("Series", true)}}, "option name"},
+		{"option twice", discordgo.ApplicationCommand{Name: "sonarrls", Description: "List series",
+			Options: []*discordgo.ApplicationCommandOption{option("series", false), option("series", false)}}, "defined twice"},
+		{"required after optional", discordgo.ApplicationCommand{Name: "sonarrls", Description: "List series",
+			Options: []*discordgo.ApplicationCommandOption{option("instance", false), option("series", true)}}, "comes after an optional one"},
+		{"too many choices", discordgo.ApplicationCommand{Name: "sonarrls", Description: "List series",
+			Options: []*discordgo.ApplicationCommandOption{withChoices(option("mode", true), choices(26, "all"))}}, "26 choices"},
+		{"long choice", discordgo.ApplicationCommand{Name: "sonarrls", Description: "List series",
+			Options: []*discordgo.ApplicationCommandOption{withChoices(option("mode", true), choices(1, strings.Repeat("x", 101)))}}, "101 characters"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateSlashCommand(&test.command)
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Errorf("validateSlashCommand() error = %v, want nil", err)
+			case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+				t.Errorf("validateSlashCommand() error = %v, want one with %q", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,389 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/fuzzy"
+	"main/sonarr"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultSonarrTimeout is used when sonarr_timeout_seconds is not set in the config file
+const defaultSonarrTimeout = 15 * time.Second
+
+// Number of days shown by !sonarrcal when not given, and the most it accepts
+const (
+	defaultCalendarDays = 7
+	maxCalendarDays     = 31
+)
+
+// Interval between command status checks and how long to wait for a command to finish
+const (
+	commandPollInterval = 2 * time.Second
+	commandPollTimeout  = 30 * time.Minute
+)
+
+// searchLimit is the number of series listed by the searches, the rest are counted
+const searchLimit = 10
+
+// clearMatchMargin is how far ahead of the second match the best one must score to be picked without asking
+const clearMatchMargin = 0.2
+
+// defaultSonarrCacheInterval is used when sonarr_cache_minutes is not set in the config file
+const defaultSonarrCacheInterval = 10 * time.Minute
+
+// handleSonarrSeriesLookup responds to the !sonarrlookup command
+func handleSonarrSeriesLookup(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr Lookup arguments:", args)
+
+	// Check if argument is provided
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "sonarrlookup")
+		return
+	}
+
+	// Call the Sonarr API
+	query := strings.Join(args, " ")
+	results, err := client.Lookup(query)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "looking up series on Sonarr", err))
+		return
+	}
+
+	// Prepare the response message with series titles
+	if len(results) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "No series found.")
+		return
+	}
+
+	// Rank the results against the query, keeping those that only match on TVDB data Sonarr does not return
+	keys := make([][]string, len(results))
+	for index, series := range results {
+		keys[index] = sonarr.SearchKeys(series)
+	}
+
+	var message string
+	for _, match := range fuzzy.Rank(query, keys, searchLimit, 0) {
+		series := results[match.Index]
+		message += fmt.Sprintf("- %s (%d) · %s\n", series.Title, series.Year, matchScore(match.Score))
+	}
+	if len(results) > searchLimit {
+		message += fmt.Sprintf("...and %d more\n", len(results)-searchLimit)
+	}
+
+	// Send the message in chunks if it's too long
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// handleSonarrLocalSeriesSearch responds to the !sonarrls command. Without --instance every configured
+// instance is searched and the results are tagged with the instance they come from.
+func handleSonarrLocalSeriesSearch(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+	log.Println("Sonarr API Local search arguments:", args)
+
+	name, args := takeFlag(args, "instance")
+
+	// Check if argument is provided
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "sonarrls")
+		return
+	}
+
+	instances := sonarrInstances
+	if name != "" {
+		instance, ok := findSonarrInstance(s, m.ChannelID, name)
+		if !ok {
+			return
+		}
+		instances = []sonarrInstance{instance}
+	} else if len(instances) == 0 {
+		s.ChannelMessageSend(m.ChannelID, sonarrNotConfigured)
+		return
+	}
+
+	// Search the series of the local Sonarr instances
+	type instanceMatch struct {
+		instance string
+		sonarr.SeriesMatch
+	}
+	var matchingSeries []instanceMatch
+	for _, instance := range instances {
+		matches, err := instance.client.SearchSeries(strings.Join(args, " "), searchLimit)
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", fmt.Sprintf("fetching series from Sonarr %s", instance.name), err))
+			continue
+		}
+		for _, match := range matches {
+			matchingSeries = append(matchingSeries, instanceMatch{instance: instance.name, SeriesMatch: match})
+		}
+	}
+
+	// Prepare the response message
+	if len(matchingSeries) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "No series found.")
+		return
+	}
+
+	sort.SliceStable(matchingSeries, func(i, j int) bool {
+		return matchingSeries[i].Score > matchingSeries[j].Score
+	})
+	matchingSeries = matchingSeries[:min(len(matchingSeries), searchLimit)]
+
+	// Build the response message
+	var message string
+	for _, match := range matchingSeries {
+		series := match.Series
+		title := fmt.Sprintf("**%s** (ID: %d)", series.Title, series.ID)
+		if len(sonarrInstances) > 1 {
+			title += fmt.Sprintf(" [%s]", match.instance)
+		}
+		message += fmt.Sprintf(
+			"%s · %s\n- Seasons: %d\n- Episodes: %d\n- Year: %d\n- Status: %s\n- Genres: %s\n\n",
+			title, matchScore(match.Score), series.Statistics.SeasonCount,
+			series.Statistics.TotalEpisodeCount, series.Year, series.Status, strings.Join(series.Genres, ", "),
+		)
+	}
+
+	// Send the response
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// autocompleteSonarrLocalSeries offers the local Sonarr series matching the typed value
+func autocompleteSonarrLocalSeries(s *discordgo.Session, i *discordgo.InteractionCreate, value string) []*discordgo.ApplicationCommandOptionChoice {
+	client := autocompleteSonarrClient(i)
+	if client == nil {
+		return nil
+	}
+
+	// Discord accepts at most 25 choices
+	var matchingSeries []sonarr.Series
+	if strings.TrimSpace(value) == "" {
+		// Nothing typed yet, offer the first series
+		allSeries, err := client.AllSeries()
+		if err != nil {
+			log.Println("Error fetching series from Sonarr:", err)
+			return nil
+		}
+		matchingSeries = allSeries[:min(len(allSeries), 25)]
+	} else {
+		matches, err := client.SearchSeries(value, 25)
+		if err != nil {
+			log.Println("Error fetching series from Sonarr:", err)
+			return nil
+		}
+		for _, match := range matches {
+			matchingSeries = append(matchingSeries, match.Series)
+		}
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, series := range matchingSeries {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  series.Title,
+			Value: series.Title,
+		})
+	}
+	return choices
+}
+
+// handleSonarrCalendar responds to the !sonarrcal command with the episodes airing in the coming days
+func handleSonarrCalendar(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	days := defaultCalendarDays
+	if len(args) > 0 {
+		var err error
+		days, err = strconv.Atoi(args[0])
+		if err != nil || days < 1 || days > maxCalendarDays {
+			sendUsage(s, m.ChannelID, "sonarrcal")
+			return
+		}
+	}
+
+	// Start at midnight today in the configured timezone
+	now := time.Now().In(botLocation)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, botLocation)
+	end := start.AddDate(0, 0, days)
+
+	episodes, err := client.Calendar(start, end)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching the calendar from Sonarr", err))
+		return
+	}
+
+	if len(episodes) == 0 {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Nothing airing in the next %d days.", days))
+		return
+	}
+
+	sort.Slice(episodes, func(i, j int) bool {
+		return episodes[i].AirDateUtc.Before(episodes[j].AirDateUtc)
+	})
+
+	// Group the episodes by day, one line per episode
+	var message string
+	var currentDay string
+	for _, episode := range episodes {
+		airTime := episode.AirDateUtc.In(botLocation)
+		if day := airTime.Format("Monday 2 January"); day != currentDay {
+			currentDay = day
+			message += fmt.Sprintf("\n**%s**\n", day)
+		}
+
+		seriesTitle := "Unknown series"
+		if episode.Series != nil {
+			seriesTitle = episode.Series.Title
+		}
+
+		status := "⏳"
+		if episode.HasFile {
+			status = "✅"
+		}
+
+		message += fmt.Sprintf("%s `%s` %s %s - %s\n",
+			status, airTime.Format("15:04"), seriesTitle, episode.Code(), episode.Title)
+	}
+
+	sendMessageChunks(s, m.ChannelID, strings.TrimPrefix(message, "\n"))
+}
+
+// matchScore formats a search score as a percentage, e.g. "87% match"
+func matchScore(score float64) string {
+	return fmt.Sprintf("%.0f%% match", score*100)
+}
+
+// clearMatch reports whether the best of the ranked matches can be picked without asking: it is the only
+// match, the only exact title match, or scores clearMatchMargin above the second one
+func clearMatch(matches []sonarr.SeriesMatch) bool {
+	switch {
+	case len(matches) == 1:
+		return true
+	case matches[0].Score == 1:
+		return matches[1].Score < 1
+	}
+	return matches[0].Score-matches[1].Score >= clearMatchMargin
+}
+
+// resolveSeries finds the local series matching query, a series ID or a title using the same search as
+// !sonarrls. An exact title match, or one scoring clearly above the others, wins. When several series match,
+// the candidates are listed in the channel so the user can run the command again with the series ID.
+func resolveSeries(s *discordgo.Session, channelID string, client sonarr.Service, query string) (sonarr.Series, bool) {
+	if id, err := strconv.Atoi(query); err == nil {
+		allSeries, err := client.AllSeries()
+		if err != nil {
+			s.ChannelMessageSend(channelID, arrErrorMessage("Sonarr", "fetching series from Sonarr", err))
+			return sonarr.Series{}, false
+		}
+		for _, series := range allSeries {
+			if series.ID == id {
+				return series, true
+			}
+		}
+	}
+
+	matchingSeries, err := client.SearchSeries(query, 0)
+	if err != nil {
+		s.ChannelMessageSend(channelID, arrErrorMessage("Sonarr", "fetching series from Sonarr", err))
+		return sonarr.Series{}, false
+	}
+
+	if len(matchingSeries) == 0 {
+		s.ChannelMessageSend(channelID, fmt.Sprintf("No series found matching %q.", query))
+		return sonarr.Series{}, false
+	}
+	if clearMatch(matchingSeries) {
+		return matchingSeries[0].Series, true
+	}
+
+	message := fmt.Sprintf("Several series match %q, run the command again with the series ID:\n", query)
+	for index, match := range matchingSeries {
+		if index == searchLimit {
+			message += fmt.Sprintf("...and %d more\n", len(matchingSeries)-index)
+			break
+		}
+		message += fmt.Sprintf("- `%d` %s (%d) · %s\n", match.Series.ID, match.Series.Title, match.Series.Year, matchScore(match.Score))
+	}
+	s.ChannelMessageSend(channelID, message)
+	return sonarr.Series{}, false
+}
+
+// waitForSonarrCommand polls a queued command until it finishes or commandPollTimeout passes.
+// onUpdate, when not nil, is called with the command each time its status changes.
+func waitForSonarrCommand(client sonarr.Service, command sonarr.Command, onUpdate func(sonarr.Command)) (sonarr.Command, error) {
+	deadline := time.Now().Add(commandPollTimeout)
+	for !command.Finished() {
+		if time.Now().After(deadline) {
+			return command, fmt.Errorf("command %d still %s after %s", command.ID, command.Status, commandPollTimeout)
+		}
+		time.Sleep(commandPollInterval)
+
+		previousStatus := command.Status
+		var err error
+		command, err = client.CommandStatus(command.ID)
+		if err != nil {
+			return command, err
+		}
+
+		if onUpdate != nil && command.Status != previousStatus {
+			onUpdate(command)
+		}
+	}
+	return command, nil
+}
+
+// trackSonarrCommand follows a queued command, editing the message with its live status and, once it
+// finishes, the outcome and how long it ran.
+func trackSonarrCommand(s *discordgo.Session, channelID, messageID string, client sonarr.Service, queued sonarr.Command, label string) {
+	render := func(command sonarr.Command) {
+		if _, err := s.ChannelMessageEdit(channelID, messageID, commandStatusLine(command, label)); err != nil {
+			log.Println("Error editing Sonarr command message:", err)
+		}
+	}
+
+	render(queued)
+	finished, err := waitForSonarrCommand(client, queued, render)
+	if err != nil {
+		s.ChannelMessageEdit(channelID, messageID, arrErrorMessage("Sonarr", fmt.Sprintf("following %s", label), err))
+		return
+	}
+	log.Printf("Sonarr command %s (%d) %s", finished.Name, finished.ID, finished.Status)
+}
+
+// commandStatusLine describes the state of a command, e.g. "✅ Backup: completed in 4s"
+func commandStatusLine(command sonarr.Command, label string) string {
+	icon := map[string]string{
+		"queued":    "⏳",
+		"started":   "🔄",
+		"completed": "✅",
+	}[command.Status]
+	if icon == "" {
+		icon = "❌"
+	}
+
+	line := fmt.Sprintf("%s %s: %s", icon, label, command.Status)
+
+	// Time the command from when it started running, or from when it was queued if it never started
+	start := command.Started
+	if start.IsZero() {
+		start = command.Queued
+	}
+	end := command.Ended
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if !start.IsZero() && command.Status != "queued" {
+		duration := end.Sub(start).Round(time.Second)
+		if command.Finished() {
+			line += fmt.Sprintf(" in %s", duration)
+		} else {
+			line += fmt.Sprintf(" for %s", duration)
+		}
+	}
+
+	if command.Message != "" && command.Status != "completed" {
+		line += fmt.Sprintf(" (%s)", command.Message)
+	}
+	return line
+}
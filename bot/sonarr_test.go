@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"main/sonarr"
+	"strings"
+	"testing"
+)
+
+// fakeSonarr is a sonarr.Service holding a list of series in memory, the methods it does not implement panic
+// on the nil Service
+type fakeSonarr struct {
+	sonarr.Service
+	series  []sonarr.Series
+	updates []sonarr.SeriesUpdate // received by UpdateSeries
+	deleted []int                 // IDs received by DeleteSeries
+}
+
+// AllSeries returns the series
+func (f *fakeSonarr) AllSeries() ([]sonarr.Series, error) {
+	return f.series, nil
+}
+
+// SearchSeries ranks the series as the cached client does
+func (f *fakeSonarr) SearchSeries(query string, limit int) ([]sonarr.SeriesMatch, error) {
+	return sonarr.RankSeries(f.series, query, limit), nil
+}
+
+// UpdateSeries records the update and applies its monitored states
+func (f *fakeSonarr) UpdateSeries(id int, update sonarr.SeriesUpdate) (sonarr.Series, error) {
+	f.updates = append(f.updates, update)
+	for index := range f.series {
+		series := &f.series[index]
+		if series.ID != id {
+			continue
+		}
+		if update.Monitored != nil {
+			series.Monitored = *update.Monitored
+		}
+		for seasonIndex := range series.Seasons {
+			if monitored, ok := update.SeasonMonitored[series.Seasons[seasonIndex].SeasonNumber]; ok {
+				series.Seasons[seasonIndex].Monitored = monitored
+			}
+		}
+		return *series, nil
+	}
+	return sonarr.Series{}, sonarr.ErrNotFound
+}
+
+// DeleteSeries records the ID of the deleted series
+func (f *fakeSonarr) DeleteSeries(id int, deleteFiles, addImportListExclusion bool) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func TestClearMatch(t *testing.T) {
+	library := []sonarr.Series{
+		{ID: 1, Title: "Doctor Who", Year: 1963},
+		{ID: 2, Title: "Doctor Who", Year: 2005},
+		{ID: 3, Title: "The Office (US)", Year: 2005},
+		{ID: 4, Title: "The Office (UK)", Year: 2001},
+		{ID: 5, Title: "Breaking Bad", Year: 2008},
+		{ID: 6, Title: "Better Call Saul", Year: 2015},
+		{ID: 7, Title: "Severance", Year: 2022},
+		{ID: 8, Title: "Money Heist", Year: 2017, AlternateTitles: []sonarr.AlternateTitle{{Title: "La Casa de Papel"}}},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  int // ID of the picked series, 0 when the user must choose
+	}{
+		{"exact title", "Severance", 7},
+		{"typo", "sevrance", 7},
+		{"clearly ahead", "breaking bad", 5},
+		{"alternate title", "la casa de papel", 8},
+		{"title and year", "doctor who 2005", 2},
+		{"same title", "doctor who", 0},
+		{"tie", "the office", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches := sonarr.RankSeries(library, test.query, 0)
+			if len(matches) == 0 {
+				t.Fatalf("RankSeries(%q) found nothing", test.query)
+			}
+
+			got := 0
+			if clearMatch(matches) {
+				got = matches[0].Series.ID
+			}
+			if got != test.want {
+				t.Errorf("clearMatch(%q) picked %d, want %d (matches %+v)", test.query, got, test.want, matches)
+			}
+		})
+	}
+}
+
+func TestResolveSeries(t *testing.T) {
+	client := &fakeSonarr{series: []sonarr.Series{
+		{ID: 3, Title: "The Office (US)", Year: 2005},
+		{ID: 4, Title: "The Office (UK)", Year: 2001},
+		{ID: 7, Title: "Severance", Year: 2022},
+	}}
+
+	tests := []struct {
+		name        string
+		query       string
+		want        int    // ID of the series found, 0 for none
+		wantMessage string // start of the message sent to the channel, if any
+	}{
+		{"by title", "severance", 7, ""},
+		{"by ID", "4", 4, ""},
+		{"several matches", "the office", 0, "Several series match \"the office\""},
+		{"no match", "zzzzzz", 0, "No series found matching \"zzzzzz\""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, sent := newTestSession(t)
+			series, ok := resolveSeries(s, "channel", client, test.query)
+			if ok != (test.want != 0) || series.ID != test.want {
+				t.Errorf("resolveSeries(%q) = %d, %v, want %d", test.query, series.ID, ok, test.want)
+			}
+
+			switch {
+			case test.wantMessage == "" && len(*sent) != 0:
+				t.Errorf("sent %q, want no message", *sent)
+			case test.wantMessage != "" && (len(*sent) != 1 || !strings.HasPrefix((*sent)[0], test.wantMessage)):
+				t.Errorf("sent %q, want a message starting with %q", *sent, test.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"main/sonarr"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sonarrAddKind sets up the add menus for series
+var sonarrAddKind = &arrAddKind{
+	app:           sonarrApp,
+	title:         "Series",
+	singular:      "series",
+	plural:        "series",
+	optionLabel:   "Monitor",
+	options:       sonarr.MonitorModes,
+	defaultOption: "all",
+	searchLabel:   "Add and search missing",
+}
+
+// sonarrAdder adds the series picked in the !sonarradd menus
+type sonarrAdder struct {
+	client  sonarr.Service
+	results []sonarr.Series // lookup results not yet in Sonarr
+}
+
+// handleSonarrAdd responds to the !sonarradd command with menus to pick the series and its options
+func handleSonarrAdd(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	handleArrAdd(s, m, sonarrAddKind, &sonarrAdder{client: client}, args)
+}
+
+// Lookup looks the series up on TheTVDB through Sonarr, keeping the ones not in Sonarr yet
+func (a *sonarrAdder) Lookup(term string, limit int) ([]arrAddResult, int, error) {
+	lookup, err := a.client.Lookup(term)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var results []arrAddResult
+	alreadyAdded := 0
+	for _, series := range lookup {
+		if series.ID != 0 {
+			alreadyAdded++
+			continue
+		}
+		if len(a.results) == limit {
+			continue
+		}
+
+		description := fmt.Sprintf("TVDB %d", series.TvdbID)
+		if series.Network != "" {
+			description = series.Network + " · " + description
+		}
+		a.results = append(a.results, series)
+		results = append(results, arrAddResult{Title: series.Title, Year: series.Year, Description: description})
+	}
+	return results, alreadyAdded, nil
+}
+
+// QualityProfiles returns the Sonarr quality profiles
+func (a *sonarrAdder) QualityProfiles() ([]arrProfile, error) {
+	profiles, err := a.client.QualityProfiles()
+	var choices []arrProfile
+	for _, profile := range profiles {
+		choices = append(choices, arrProfile{ID: profile.ID, Name: profile.Name})
+	}
+	return choices, err
+}
+
+// RootFolders returns the Sonarr root folders
+func (a *sonarrAdder) RootFolders() ([]arrRootFolder, error) {
+	folders, err := a.client.RootFolders()
+	var choices []arrRootFolder
+	for _, folder := range folders {
+		choices = append(choices, arrRootFolder{Path: folder.Path, FreeSpace: folder.FreeSpace})
+	}
+	return choices, err
+}
+
+// Add posts the chosen series to Sonarr, monitored as picked in the last menu
+func (a *sonarrAdder) Add(choices arrAddChoices, search bool) (string, error) {
+	series := a.results[choices.Result]
+
+	// Sonarr v3 requires a language profile, v4 removed them
+	languageProfiles, err := a.client.LanguageProfiles()
+	if err != nil && !errors.Is(err, sonarr.ErrNotFound) {
+		return "", fmt.Errorf("error fetching language profiles: %w", err)
+	}
+	languageProfileID := 0
+	if len(languageProfiles) > 0 {
+		languageProfileID = languageProfiles[0].ID
+	}
+
+	added, err := a.client.AddSeries(sonarr.NewSeries{
+		Title:             series.Title,
+		TitleSlug:         series.TitleSlug,
+		TvdbID:            series.TvdbID,
+		Year:              series.Year,
+		Images:            series.Images,
+		Seasons:           series.Seasons,
+		QualityProfileID:  choices.ProfileID,
+		LanguageProfileID: languageProfileID,
+		RootFolderPath:    choices.RootPath,
+		Monitored:         choices.Option != "none",
+		SeasonFolder:      true,
+		AddOptions: sonarr.AddOptions{
+			Monitor:                  choices.Option,
+			SearchForMissingEpisodes: search,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	content := fmt.Sprintf("✅ Added **%s** (%d) to Sonarr (ID: %d), monitoring: %s.", added.Title, added.Year, added.ID, choices.Option)
+	if search {
+		content += " Searching for missing episodes."
+	}
+	return content, nil
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/sonarr"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sonarrCommands maps the !sonarrcmd jobs to the Sonarr command names, the series variant is used
+// when a series is given (empty when the job does not take one).
+var sonarrCommands = map[string]struct {
+	name       string
+	seriesName string
+}{
+	"rescan":        {name: "RescanSeries", seriesName: "RescanSeries"},
+	"refresh":       {name: "RefreshSeries", seriesName: "RefreshSeries"},
+	"rss":           {name: "RssSync"},
+	"backup":        {name: "Backup"},
+	"missingsearch": {name: "MissingEpisodeSearch", seriesName: "SeriesSearch"},
+}
+
+// handleSonarrCommand responds to the !sonarrcmd command, running a Sonarr job and following its progress
+func handleSonarrCommand(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr command arguments:", args)
+
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "sonarrcmd")
+		return
+	}
+
+	job, ok := sonarrCommands[strings.ToLower(args[0])]
+	if !ok {
+		sendUsage(s, m.ChannelID, "sonarrcmd")
+		return
+	}
+
+	command := sonarr.NewCommand{Name: job.name}
+	label := job.name
+
+	if len(args) > 1 {
+		if job.seriesName == "" {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("`%s` does not take a series.", strings.ToLower(args[0])))
+			return
+		}
+
+		series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args[1:], " "))
+		if !ok {
+			return
+		}
+		command = sonarr.NewCommand{Name: job.seriesName, SeriesID: series.ID}
+		label = fmt.Sprintf("%s of %s", job.seriesName, series.Title)
+	}
+
+	queued, err := client.RunCommand(command)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", fmt.Sprintf("queueing %s", label), err))
+		return
+	}
+	log.Printf("Sonarr command %s (%d) queued by %s", queued.Name, queued.ID, m.Author.Username)
+
+	message, err := s.ChannelMessageSend(m.ChannelID, commandStatusLine(queued, label))
+	if err != nil {
+		log.Println("Error sending Sonarr command message:", err)
+		return
+	}
+
+	// Following the command can take up to commandPollTimeout, do it in the background so the concurrent
+	// command slot held by this handler is released straight away
+	go trackSonarrCommand(s, m.ChannelID, message.ID, client, queued, label)
+}
@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/format"
+	"main/sonarr"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// deleteConfirmTimeout is how long the user has to confirm a !sonarrdelete
+const deleteConfirmTimeout = 2 * time.Minute
+
+// sonarrDeleteRequest is the state of a !sonarrdelete message waiting for confirmation
+type sonarrDeleteRequest struct {
+	client    sonarr.Service
+	series    sonarr.Series
+	exclusion bool // add an import list exclusion so the series is not added back
+}
+
+// sonarrDeleteRequests holds the pending !sonarrdelete confirmations by message ID
+var sonarrDeleteRequests = newSessionStore[*sonarrDeleteRequest](deleteConfirmTimeout)
+
+// handleSonarrDelete responds to the !sonarrdelete command with what will be removed and the confirmation buttons
+func handleSonarrDelete(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr delete arguments:", args)
+
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "sonarrdelete")
+		return
+	}
+
+	series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args, " "))
+	if !ok {
+		return
+	}
+
+	request := &sonarrDeleteRequest{client: client, series: series}
+	message, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content:    request.content(),
+		Components: request.components(),
+	})
+	if err != nil {
+		log.Println("Error sending Sonarr delete message:", err)
+		return
+	}
+
+	sonarrDeleteRequests.put(message.ID, m.Author.ID, request)
+}
+
+// handleSonarrDeleteComponent handles the buttons of a !sonarrdelete message
+func handleSonarrDeleteComponent(s *discordgo.Session, i *discordgo.InteractionCreate, action string, values []string) {
+	request, ok := sonarrDeleteRequests.get(s, i)
+	if !ok {
+		return
+	}
+
+	switch action {
+	case "exclusion":
+		request.exclusion = !request.exclusion
+		respondUpdate(s, i, request.content(), request.components())
+		return
+	case "cancel":
+		sonarrDeleteRequests.remove(i.Message.ID)
+		respondUpdate(s, i, fmt.Sprintf("Delete of **%s** cancelled.", request.series.Title), nil)
+		return
+	case "delete", "deletefiles":
+	default:
+		// Never delete on an action this handler does not know, e.g. a renamed button
+		log.Println("Unknown Sonarr delete action:", action)
+		return
+	}
+
+	// Check again in case the permissions changed since the command was run
+	if !requireComponentPermission(s, i, PermissionAdmin, "delete series from Sonarr") {
+		return
+	}
+	sonarrDeleteRequests.remove(i.Message.ID)
+
+	deleteFiles := action == "deletefiles"
+	series := request.series
+
+	// Acknowledge straight away, deleting the files can take longer than Discord waits for an answer
+	respondUpdate(s, i, fmt.Sprintf("Deleting **%s** from Sonarr...", series.Title), nil)
+
+	if err := request.client.DeleteSeries(series.ID, deleteFiles, request.exclusion); err != nil {
+		editSonarrDeleteMessage(s, i, arrErrorMessage("Sonarr", fmt.Sprintf("deleting %s from Sonarr", series.Title), err))
+		return
+	}
+
+	user := interactionUser(i)
+	log.Printf("Sonarr series %s (%d) deleted by %s (files: %v, exclusion: %v)",
+		series.Title, series.ID, user.Username, deleteFiles, request.exclusion)
+
+	content := fmt.Sprintf("🗑️ Deleted **%s** from Sonarr.", series.Title)
+	if deleteFiles {
+		content += fmt.Sprintf(" %s of files were deleted.", format.Bytes(series.Statistics.SizeOnDisk))
+	} else {
+		content += " The files were kept on disk."
+	}
+	if request.exclusion {
+		content += " It was added to the import list exclusions."
+	}
+	editSonarrDeleteMessage(s, i, content)
+}
+
+// editSonarrDeleteMessage replaces the confirmation message, already acknowledged, with the outcome of the delete
+func editSonarrDeleteMessage(s *discordgo.Session, i *discordgo.InteractionCreate, content string) {
+	if _, err := s.ChannelMessageEdit(i.ChannelID, i.Message.ID, content); err != nil {
+		log.Println("Error editing Sonarr delete message:", err)
+	}
+}
+
+// content describes what will be removed
+func (r *sonarrDeleteRequest) content() string {
+	series := r.series
+	return fmt.Sprintf(
+		"⚠️ Delete **%s** (%d) from Sonarr?\n- Path: `%s`\n- Episodes on disk: %d of %d\n- Size on disk: %s\n\nConfirm within %d minutes, this cannot be undone.",
+		series.Title, series.Year, series.Path, series.Statistics.EpisodeFileCount,
+		series.Statistics.TotalEpisodeCount, format.Bytes(series.Statistics.SizeOnDisk), int(deleteConfirmTimeout.Minutes()),
+	)
+}
+
+// components renders the confirmation buttons with the current import list exclusion choice
+func (r *sonarrDeleteRequest) components() []discordgo.MessageComponent {
+	exclusionLabel := "Import list exclusion: off"
+	if r.exclusion {
+		exclusionLabel = "Import list exclusion: on"
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{CustomID: "sonarrdelete:delete", Label: "Delete, keep files", Style: discordgo.DangerButton},
+			discordgo.Button{CustomID: "sonarrdelete:deletefiles", Label: "Delete with files", Style: discordgo.DangerButton},
+			discordgo.Button{CustomID: "sonarrdelete:exclusion", Label: exclusionLabel, Style: discordgo.SecondaryButton},
+			discordgo.Button{CustomID: "sonarrdelete:cancel", Label: "Cancel", Style: discordgo.SecondaryButton},
+		}},
+	}
+}
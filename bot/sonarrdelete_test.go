@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"main/auth"
+	"main/sonarr"
+	"slices"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestHandleSonarrDeleteComponent(t *testing.T) {
+	permissionConfig = auth.PermissionConfig{Admin: auth.PermissionRule{Users: []string{"user"}}}
+	t.Cleanup(func() { permissionConfig = auth.PermissionConfig{} })
+
+	tests := []struct {
+		action      string
+		wantDeleted []int
+		wantPending bool // the confirmation is still waiting for a button
+	}{
+		{"delete", []int{7}, false},
+		{"deletefiles", []int{7}, false},
+		{"cancel", nil, false},
+		{"exclusion", nil, true},
+		{"deleteall", nil, true},
+		{"", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.action, func(t *testing.T) {
+			s, _ := newTestSession(t)
+			client := &fakeSonarr{series: []sonarr.Series{{ID: 7, Title: "Severance"}}}
+			sonarrDeleteRequests.put("message", "user", &sonarrDeleteRequest{client: client, series: client.series[0]})
+			t.Cleanup(func() { sonarrDeleteRequests.remove("message") })
+
+			i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+				ID:        "interaction",
+				ChannelID: "channel",
+				Message:   &discordgo.Message{ID: "message", ChannelID: "channel"},
+				User:      &discordgo.User{ID: "user", Username: "tester"},
+			}}
+			handleSonarrDeleteComponent(s, i, test.action, nil)
+
+			if !slices.Equal(client.deleted, test.wantDeleted) {
+				t.Errorf("deleted %v, want %v", client.deleted, test.wantDeleted)
+			}
+			_, pending := sonarrDeleteRequests.sessions["message"]
+			if pending != test.wantPending {
+				t.Errorf("pending = %v, want %v", pending, test.wantPending)
+			}
+		})
+	}
+}
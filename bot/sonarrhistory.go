@@ -0,0 +1,138 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/sonarr"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// historyLimit is the number of events shown by !sonarrhistory
+const historyLimit = 25
+
+// historyEvents maps the history event types shown by !sonarrhistory to their label
+var historyEvents = map[string]string{
+	"grabbed":                "📥 Grabbed",
+	"downloadFolderImported": "✅ Imported",
+	"downloadFailed":         "❌ Failed",
+	"episodeFileDeleted":     "🗑️ Deleted",
+}
+
+// handleSonarrHistory responds to the !sonarrhistory command with the recent grabs, imports, failures and
+// deletions, of every series or of one, optionally limited to the events after --since.
+func handleSonarrHistory(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr history arguments:", args)
+
+	flags, args := splitFlags(args, "since")
+
+	var since time.Time
+	if value, ok := flags["since"]; ok {
+		duration, err := parseSince(value)
+		if err != nil {
+			sendUsage(s, m.ChannelID, "sonarrhistory")
+			return
+		}
+		since = time.Now().Add(-duration)
+	}
+
+	var records []sonarr.HistoryRecord
+	var err error
+	switch {
+	case len(args) > 0:
+		series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args, " "))
+		if !ok {
+			return
+		}
+		records, err = client.SeriesHistory(series.ID)
+	case !since.IsZero():
+		records, err = client.HistorySince(since)
+	default:
+		// Fetch more than shown as the events not listed by this command are dropped
+		var page sonarr.HistoryPage
+		page, err = client.History(1, historyLimit*4)
+		records = page.Records
+	}
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching the history from Sonarr", err))
+		return
+	}
+
+	var events []sonarr.HistoryRecord
+	for _, record := range records {
+		if _, ok := historyEvents[record.EventType]; ok && record.Date.After(since) {
+			events = append(events, record)
+		}
+	}
+
+	if len(events) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "No grabs, imports, failures or deletions found.")
+		return
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Date.After(events[j].Date)
+	})
+
+	var message string
+	for index, record := range events {
+		if index == historyLimit {
+			message += fmt.Sprintf("...and %d older events\n", len(events)-index)
+			break
+		}
+		message += historyLine(record)
+	}
+
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// historyLine describes a history event on two lines, the episode and release details then the release name
+func historyLine(record sonarr.HistoryRecord) string {
+	episode := fmt.Sprintf("episode %d", record.EpisodeID)
+	if record.Episode != nil {
+		episode = record.Episode.Code()
+	}
+	if record.Series != nil {
+		episode = record.Series.Title + " " + episode
+	}
+
+	var details []string
+	for _, value := range []string{record.Quality.Name(), record.Data["releaseGroup"], record.Data["indexer"]} {
+		if value != "" {
+			details = append(details, value)
+		}
+	}
+
+	line := fmt.Sprintf("`%s` %s %s (%s)\n",
+		record.Date.In(botLocation).Format("Jan 02 15:04"), historyEvents[record.EventType], episode, strings.Join(details, " · "))
+	line += fmt.Sprintf("> `%s`\n", record.SourceTitle)
+
+	// Failures carry the download client message, deletions the reason
+	if reason := record.Data["message"]; reason != "" {
+		line += fmt.Sprintf("> %s\n", reason)
+	} else if reason := record.Data["reason"]; reason != "" {
+		line += fmt.Sprintf("> Reason: %s\n", reason)
+	}
+	return line
+}
+
+// parseSince parses a --since value such as 90m, 24h or 7d
+func parseSince(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("invalid number of days %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err == nil && duration <= 0 {
+		err = fmt.Errorf("duration %q is not positive", value)
+	}
+	return duration, err
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/format"
+	"main/sonarr"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleSonarrInfo responds to the !sonarrinfo command with the details of a series and its seasons
+func handleSonarrInfo(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr info arguments:", args)
+
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "sonarrinfo")
+		return
+	}
+
+	series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args, " "))
+	if !ok {
+		return
+	}
+
+	s.ChannelMessageSendEmbed(m.ChannelID, seriesEmbed(series))
+}
+
+// seriesEmbed renders a series with its poster, overview and per-season breakdown
+func seriesEmbed(series sonarr.Series) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s (%d)", series.Title, series.Year),
+		Description: format.Truncate(series.Overview, 1000),
+		Color:       0x35c5f4, // Sonarr blue
+		Footer:      &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Sonarr ID %d", series.ID)},
+	}
+	if series.TvdbID != 0 {
+		embed.URL = fmt.Sprintf("https://thetvdb.com/?tab=series&id=%d", series.TvdbID)
+	}
+	if poster := series.Poster(); poster != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: poster}
+	}
+
+	nextAiring := "-"
+	if series.NextAiring != nil {
+		nextAiring = series.NextAiring.In(botLocation).Format("Mon 2 Jan 2006 15:04")
+	}
+
+	network := series.Network
+	if network == "" {
+		network = "-"
+	}
+
+	// Discord rejects embeds with an empty field
+	status := series.Status
+	if status == "" {
+		status = "unknown"
+	}
+
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Status", Value: status, Inline: true},
+		{Name: "Network", Value: network, Inline: true},
+		{Name: "Monitored", Value: yesNo(series.Monitored), Inline: true},
+		{Name: "Episodes", Value: fmt.Sprintf("%d / %d", series.Statistics.EpisodeFileCount, series.Statistics.EpisodeCount), Inline: true},
+		{Name: "Size on disk", Value: format.Bytes(series.Statistics.SizeOnDisk), Inline: true},
+		{Name: "Next airing", Value: nextAiring, Inline: true},
+	}
+
+	if table := seasonTable(series.Seasons); table != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Seasons", Value: table})
+	}
+
+	return embed
+}
+
+// seasonTable lists the files, episodes, size and monitored state of each season in a code block
+func seasonTable(seasons []sonarr.Season) string {
+	if len(seasons) == 0 {
+		return ""
+	}
+
+	// An embed field holds at most 1024 characters, keep room for the code block markers
+	const fieldLimit = 1024 - 8
+
+	rows := fmt.Sprintf("%-6s %-9s %-10s %s\n", "Season", "Files", "Size", "Monitored")
+	for _, season := range seasons {
+		files, size := "-", "-"
+		if season.Statistics != nil {
+			files = fmt.Sprintf("%d/%d", season.Statistics.EpisodeFileCount, season.Statistics.EpisodeCount)
+			size = format.Bytes(season.Statistics.SizeOnDisk)
+		}
+
+		row := fmt.Sprintf("%-6d %-9s %-10s %s\n", season.SeasonNumber, files, size, yesNo(season.Monitored))
+		if len(rows)+len(row) > fieldLimit {
+			break
+		}
+		rows += row
+	}
+
+	return "```\n" + rows + "```"
+}
+
+// yesNo formats a boolean for display
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/api"
+	"main/auth"
+	"main/sonarr"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// legacySonarrInstance names the instance set by sonarr_instance and sonarr_port in the config file
+const legacySonarrInstance = "default"
+
+// sonarrNotConfigured is sent when a Sonarr command is run without any instance configured
+const sonarrNotConfigured = "Sonarr is not configured, check the config and credentials files."
+
+// sonarrInstance is a configured Sonarr instance, its client keeps the series list in memory
+type sonarrInstance struct {
+	name   string
+	client *sonarr.CachedClient
+}
+
+// sonarrInstances lists the configured Sonarr instances, the default one first, empty when Sonarr is not configured
+var sonarrInstances []sonarrInstance
+
+// SonarrCommandHandler defines the signature of a Sonarr command, called with the client of the instance to use
+type SonarrCommandHandler func(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string)
+
+// sonarrHandler adapts a Sonarr command to a CommandHandler. The "--instance <name>" flag is removed from the
+// arguments and picks the instance the command runs against, the default instance is used without it.
+func sonarrHandler(handler SonarrCommandHandler) CommandHandler {
+	return func(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+		name, args := takeFlag(args, "instance")
+		client, ok := requireSonarr(s, m.ChannelID, name)
+		if !ok {
+			return
+		}
+		handler(s, m, client, args)
+	}
+}
+
+// loadSonarrInstances creates the clients of the instance set by sonarr_instance and of the instances
+// listed in sonarr_instances, then moves sonarr_default_instance to the front
+func loadSonarrInstances(config auth.Config, creds auth.Auth) {
+	cacheInterval := minutesOrDefault(config.SonarrCacheMinutes, defaultSonarrCacheInterval)
+	sonarrInstances = nil
+
+	add := func(name string, instanceConfig sonarr.Config) {
+		client := sonarr.NewCachedClient(sonarr.NewClient(instanceConfig), cacheInterval)
+		sonarrInstances = append(sonarrInstances, sonarrInstance{name: name, client: client})
+	}
+
+	if config.SonarrInstance != "" {
+		add(legacySonarrInstance, sonarr.Config{
+			BaseURL: sonarr.BaseURL(config.SonarrInstance, config.SonarrPort),
+			APIKey:  creds.SonarrApiToken,
+			Timeout: secondsOrDefault(config.SonarrTimeoutSeconds, defaultSonarrTimeout),
+			Retry:   api.RetryPolicy{Retries: config.SonarrRetries, Backoff: time.Second},
+		})
+	}
+
+	for _, instance := range config.SonarrInstances {
+		name := strings.ToLower(instance.Name)
+		if name == "" || name == "all" {
+			log.Printf("Skipping Sonarr instance %q: it needs a name other than all", instance.Name)
+			continue
+		}
+		if _, found := lookupSonarrInstance(name); found {
+			log.Printf("Skipping Sonarr instance %q: the name is already used", instance.Name)
+			continue
+		}
+		if creds.SonarrApiTokens[instance.Name] == "" {
+			log.Printf("Sonarr instance %q has no API key in sonarr_api_tokens", instance.Name)
+		}
+
+		add(name, sonarr.Config{
+			BaseURL: sonarr.BaseURL(instance.Instance, instance.Port),
+			APIKey:  creds.SonarrApiTokens[instance.Name],
+			Timeout: secondsOrDefault(instance.TimeoutSeconds, defaultSonarrTimeout),
+			Retry:   api.RetryPolicy{Retries: instance.Retries, Backoff: time.Second},
+		})
+	}
+
+	if config.SonarrDefaultInstance != "" {
+		for index, instance := range sonarrInstances {
+			if instance.name == strings.ToLower(config.SonarrDefaultInstance) {
+				sonarrInstances[0], sonarrInstances[index] = sonarrInstances[index], sonarrInstances[0]
+			}
+		}
+	}
+}
+
+// lookupSonarrInstance returns the instance with the given name, or the default instance when name is empty
+func lookupSonarrInstance(name string) (sonarrInstance, bool) {
+	if len(sonarrInstances) == 0 {
+		return sonarrInstance{}, false
+	}
+	if name == "" {
+		return sonarrInstances[0], true
+	}
+	for _, instance := range sonarrInstances {
+		if strings.EqualFold(instance.name, name) {
+			return instance, true
+		}
+	}
+	return sonarrInstance{}, false
+}
+
+// findSonarrInstance returns the instance with the given name, or the default instance when name is empty,
+// telling the channel when Sonarr is not configured or there is no such instance
+func findSonarrInstance(s *discordgo.Session, channelID, name string) (sonarrInstance, bool) {
+	if len(sonarrInstances) == 0 {
+		s.ChannelMessageSend(channelID, sonarrNotConfigured)
+		return sonarrInstance{}, false
+	}
+
+	instance, ok := lookupSonarrInstance(name)
+	if !ok {
+		s.ChannelMessageSend(channelID, fmt.Sprintf("Unknown Sonarr instance %q, the instances are: %s",
+			name, strings.Join(sonarrInstanceNames(), ", ")))
+	}
+	return instance, ok
+}
+
+// requireSonarr returns the client of the named instance, see findSonarrInstance
+func requireSonarr(s *discordgo.Session, channelID, name string) (sonarr.Service, bool) {
+	instance, ok := findSonarrInstance(s, channelID, name)
+	if !ok {
+		return nil, false
+	}
+	return instance.client, true
+}
+
+// sonarrInstanceNames returns the names of the configured instances, the default one first
+func sonarrInstanceNames() []string {
+	var names []string
+	for _, instance := range sonarrInstances {
+		names = append(names, instance.name)
+	}
+	return names
+}
+
+// autocompleteSonarrClient returns the client of the instance chosen in the instance option of a slash
+// command, the default instance when none is chosen, nil when Sonarr is not configured
+func autocompleteSonarrClient(i *discordgo.InteractionCreate) sonarr.Service {
+	instance, ok := lookupSonarrInstance(optionValue(i.ApplicationCommandData().Options, "instance"))
+	if !ok {
+		return nil
+	}
+	return instance.client
+}
+
+// addSonarrInstanceOption adds an instance option to the slash command of every Sonarr command when several
+// instances are configured. Commands with subcommands get the option on each subcommand.
+func addSonarrInstanceOption() {
+	if len(sonarrInstances) < 2 {
+		return
+	}
+
+	instanceOption := func() *discordgo.ApplicationCommandOption {
+		option := &discordgo.ApplicationCommandOption{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "instance",
+			Description: fmt.Sprintf("Sonarr instance, %s when not set", sonarrInstances[0].name),
+		}
+		for _, name := range sonarrInstanceNames() {
+			option.Choices = append(option.Choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+		}
+		return option
+	}
+
+	for _, cmd := range Commands.Commands() {
+		if cmd.Category != CategorySonarr {
+			continue
+		}
+
+		cmd.Flags = append(cmd.Flags, "instance")
+		subcommands := false
+		for _, option := range cmd.Options {
+			if option.Type == discordgo.ApplicationCommandOptionSubCommand {
+				option.Options = append(option.Options, instanceOption())
+				subcommands = true
+			}
+		}
+		if !subcommands {
+			cmd.Options = append(cmd.Options, instanceOption())
+		}
+	}
+}
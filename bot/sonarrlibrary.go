@@ -0,0 +1,303 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/format"
+	"main/sonarr"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sonarrSettings are the series settings !sonarrset changes
+var sonarrSettings = []string{"profile", "tag", "root"}
+
+// handleSonarrProfiles responds to the !sonarrprofiles command with the quality profiles and how many series use them
+func handleSonarrProfiles(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	profiles, err := client.QualityProfiles()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching quality profiles from Sonarr", err))
+		return
+	}
+	allSeries, err := client.AllSeries()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching series from Sonarr", err))
+		return
+	}
+
+	if len(profiles) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "Sonarr has no quality profiles.")
+		return
+	}
+
+	message := "**Quality profiles**\n"
+	for _, profile := range profiles {
+		count := 0
+		for _, series := range allSeries {
+			if series.QualityProfileID == profile.ID {
+				count++
+			}
+		}
+		message += fmt.Sprintf("- `%d` %s (%d series)\n", profile.ID, profile.Name, count)
+	}
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// handleSonarrTags responds to the !sonarrtags command with the tags and how many series carry them
+func handleSonarrTags(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	tags, err := client.Tags()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching tags from Sonarr", err))
+		return
+	}
+	allSeries, err := client.AllSeries()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching series from Sonarr", err))
+		return
+	}
+
+	if len(tags) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "Sonarr has no tags.")
+		return
+	}
+
+	message := "**Tags**\n"
+	for _, tag := range tags {
+		count := 0
+		for _, series := range allSeries {
+			if slices.Contains(series.Tags, tag.ID) {
+				count++
+			}
+		}
+		message += fmt.Sprintf("- `%d` %s (%d series)\n", tag.ID, tag.Label, count)
+	}
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// handleSonarrRoots responds to the !sonarrroots command with the root folders and their free space
+func handleSonarrRoots(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	rootFolders, err := client.RootFolders()
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching root folders from Sonarr", err))
+		return
+	}
+
+	if len(rootFolders) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "Sonarr has no root folders.")
+		return
+	}
+
+	message := "**Root folders**\n"
+	for _, folder := range rootFolders {
+		line := fmt.Sprintf("- `%d` `%s` %s free", folder.ID, folder.Path, format.Bytes(folder.FreeSpace))
+		if !folder.Accessible {
+			line += " ⚠️ not accessible"
+		}
+		message += line + "\n"
+	}
+	sendMessageChunks(s, m.ChannelID, message)
+}
+
+// handleSonarrSet responds to the !sonarrset command, changing the quality profile, tags or root folder of a series
+func handleSonarrSet(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr set arguments:", args)
+
+	// The setting is the last profile, tag or root word that is followed by a value, the series comes before it
+	settingIndex := -1
+	for index := len(args) - 2; index > 0; index-- {
+		if slices.Contains(sonarrSettings, strings.ToLower(args[index])) {
+			settingIndex = index
+			break
+		}
+	}
+	if settingIndex < 0 {
+		sendUsage(s, m.ChannelID, "sonarrset")
+		return
+	}
+	setting := strings.ToLower(args[settingIndex])
+	value := strings.Join(args[settingIndex+1:], " ")
+
+	series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args[:settingIndex], " "))
+	if !ok {
+		return
+	}
+
+	var update sonarr.SeriesUpdate
+	var description string
+	switch setting {
+	case "profile":
+		profile, ok := findQualityProfile(s, m.ChannelID, client, value)
+		if !ok {
+			return
+		}
+		update.QualityProfileID = &profile.ID
+		description = fmt.Sprintf("quality profile set to **%s**", profile.Name)
+	case "tag":
+		tag, remove, ok := findTag(s, m.ChannelID, client, value)
+		if !ok {
+			return
+		}
+		if remove {
+			update.RemoveTags = []int{tag.ID}
+			description = fmt.Sprintf("tag **%s** removed", tag.Label)
+		} else {
+			update.AddTags = []int{tag.ID}
+			description = fmt.Sprintf("tag **%s** added", tag.Label)
+		}
+	case "root":
+		folder, ok := findRootFolder(s, m.ChannelID, client, value)
+		if !ok {
+			return
+		}
+		if strings.EqualFold(folder.Path, series.RootFolderPath) {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("**%s** is already in `%s`.", series.Title, folder.Path))
+			return
+		}
+		update.RootFolderPath = folder.Path
+		description = fmt.Sprintf("moving to `%s`, Sonarr moves the files in the background", folder.Path)
+	}
+
+	updated, err := client.UpdateSeries(series.ID, update)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", fmt.Sprintf("updating %s on Sonarr", series.Title), err))
+		return
+	}
+	log.Printf("Sonarr series %s (%d) %s by %s", updated.Title, updated.ID, setting, m.Author.Username)
+
+	s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("✅ **%s**: %s.", updated.Title, description))
+}
+
+// findQualityProfile returns the quality profile with the given ID or name, listing the profiles when there is none
+func findQualityProfile(s *discordgo.Session, channelID string, client sonarr.Service, value string) (sonarr.QualityProfile, bool) {
+	profiles, err := client.QualityProfiles()
+	if err != nil {
+		s.ChannelMessageSend(channelID, arrErrorMessage("Sonarr", "fetching quality profiles from Sonarr", err))
+		return sonarr.QualityProfile{}, false
+	}
+
+	var names []string
+	for _, profile := range profiles {
+		if strconv.Itoa(profile.ID) == value || strings.EqualFold(profile.Name, value) {
+			return profile, true
+		}
+		names = append(names, profile.Name)
+	}
+
+	s.ChannelMessageSend(channelID, fmt.Sprintf("No quality profile %q, pick one of: %s", value, strings.Join(names, ", ")))
+	return sonarr.QualityProfile{}, false
+}
+
+// findTag returns the tag named by value, "+label" or "label" to add it and "-label" to remove it.
+// A tag that is added but does not exist yet is created.
+func findTag(s *discordgo.Session, channelID string, client sonarr.Service, value string) (tag sonarr.Tag, remove bool, ok bool) {
+	label := strings.TrimPrefix(value, "+")
+	if removed, found := strings.CutPrefix(value, "-"); found {
+		label, remove = removed, true
+	}
+	label = strings.TrimSpace(label)
+	if label == "" {
+		sendUsage(s, channelID, "sonarrset")
+		return tag, remove, false
+	}
+
+	tags, err := client.Tags()
+	if err != nil {
+		s.ChannelMessageSend(channelID, arrErrorMessage("Sonarr", "fetching tags from Sonarr", err))
+		return tag, remove, false
+	}
+	for _, existing := range tags {
+		if strconv.Itoa(existing.ID) == label || strings.EqualFold(existing.Label, label) {
+			return existing, remove, true
+		}
+	}
+
+	if remove {
+		s.ChannelMessageSend(channelID, fmt.Sprintf("No tag %q.", label))
+		return tag, remove, false
+	}
+
+	tag, err = client.CreateTag(strings.ToLower(label))
+	if err != nil {
+		s.ChannelMessageSend(channelID, arrErrorMessage("Sonarr", fmt.Sprintf("creating the tag %s on Sonarr", label), err))
+		return tag, remove, false
+	}
+	return tag, remove, true
+}
+
+// findRootFolder returns the root folder with the given ID or path, listing the root folders when there is none
+func findRootFolder(s *discordgo.Session, channelID string, client sonarr.Service, value string) (sonarr.RootFolder, bool) {
+	rootFolders, err := client.RootFolders()
+	if err != nil {
+		s.ChannelMessageSend(channelID, arrErrorMessage("Sonarr", "fetching root folders from Sonarr", err))
+		return sonarr.RootFolder{}, false
+	}
+
+	var paths []string
+	for _, folder := range rootFolders {
+		if strconv.Itoa(folder.ID) == value || strings.EqualFold(strings.TrimRight(folder.Path, `/\`), strings.TrimRight(value, `/\`)) {
+			return folder, true
+		}
+		paths = append(paths, fmt.Sprintf("`%s`", folder.Path))
+	}
+
+	s.ChannelMessageSend(channelID, fmt.Sprintf("No root folder %q, pick one of: %s", value, strings.Join(paths, ", ")))
+	return sonarr.RootFolder{}, false
+}
+
+// autocompleteSonarrSet offers the series for the series option and, for the value option, the quality
+// profiles, tags or root folders depending on the chosen setting
+func autocompleteSonarrSet(s *discordgo.Session, i *discordgo.InteractionCreate, value string) []*discordgo.ApplicationCommandOptionChoice {
+	data := i.ApplicationCommandData()
+	focused := focusedOption(data.Options)
+	if focused == nil || focused.Name != "value" {
+		return autocompleteSonarrLocalSeries(s, i, value)
+	}
+	client := autocompleteSonarrClient(i)
+	if client == nil {
+		return nil
+	}
+
+	var names []string
+	switch optionValue(data.Options, "setting") {
+	case "profile":
+		profiles, err := client.QualityProfiles()
+		if err != nil {
+			log.Println("Error fetching quality profiles from Sonarr:", err)
+		}
+		for _, profile := range profiles {
+			names = append(names, profile.Name)
+		}
+	case "tag":
+		tags, err := client.Tags()
+		if err != nil {
+			log.Println("Error fetching tags from Sonarr:", err)
+		}
+		for _, tag := range tags {
+			names = append(names, "+"+tag.Label, "-"+tag.Label)
+		}
+	case "root":
+		rootFolders, err := client.RootFolders()
+		if err != nil {
+			log.Println("Error fetching root folders from Sonarr:", err)
+		}
+		for _, folder := range rootFolders {
+			names = append(names, folder.Path)
+		}
+	}
+
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, name := range names {
+		if !strings.Contains(strings.ToLower(name), strings.ToLower(value)) {
+			continue
+		}
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: name, Value: name})
+		// Discord accepts at most 25 choices
+		if len(choices) == 25 {
+			break
+		}
+	}
+	return choices
+}
@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/sonarr"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// missingPageSize is the number of missing episodes shown by !sonarrmissing without a series
+const missingPageSize = 50
+
+// sonarrSearchRequest is the state of the search buttons sent by !sonarrmissing
+type sonarrSearchRequest struct {
+	client     sonarr.Service
+	episodeIDs []int
+	series     sonarr.Series // zero when listing the missing episodes of every series
+}
+
+// sonarrSearchRequests holds the pending search buttons by message ID
+var sonarrSearchRequests = newSessionStore[*sonarrSearchRequest](30 * time.Minute)
+
+// handleSonarrMissing responds to the !sonarrmissing command with the missing monitored episodes,
+// of every series or of the one matching the arguments, followed by buttons to search for them.
+func handleSonarrMissing(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr missing arguments:", args)
+
+	request := &sonarrSearchRequest{client: client}
+	var episodes []sonarr.Episode
+	var total int
+
+	if len(args) == 0 {
+		missing, err := client.MissingEpisodes(1, missingPageSize)
+		if err != nil {
+			s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching missing episodes from Sonarr", err))
+			return
+		}
+		episodes, total = missing.Records, missing.TotalRecords
+	} else {
+		series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args, " "))
+		if !ok {
+			return
+		}
+		request.series = series
+
+		episodes, ok = missingSeriesEpisodes(s, m.ChannelID, client, series)
+		if !ok {
+			return
+		}
+		total = len(episodes)
+	}
+
+	if len(episodes) == 0 {
+		s.ChannelMessageSend(m.ChannelID, "No missing episodes 🎉")
+		return
+	}
+
+	message := fmt.Sprintf("**%d missing episodes**\n", total)
+	for _, episode := range episodes {
+		request.episodeIDs = append(request.episodeIDs, episode.ID)
+
+		seriesTitle := request.series.Title
+		if episode.Series != nil {
+			seriesTitle = episode.Series.Title
+		}
+		message += fmt.Sprintf("- %s %s - %s (aired %s)\n",
+			seriesTitle, episode.Code(), episode.Title, episode.AirDateUtc.In(botLocation).Format("2 Jan 2006"))
+	}
+	if total > len(episodes) {
+		message += fmt.Sprintf("Showing the %d most recent.\n", len(episodes))
+	}
+	sendMessageChunks(s, m.ChannelID, message)
+
+	// The buttons go in their own message as the list may have been split over several
+	buttons := []discordgo.MessageComponent{
+		discordgo.Button{
+			CustomID: "sonarrmissing:episodes",
+			Label:    fmt.Sprintf("Search these %d episodes", len(request.episodeIDs)),
+			Style:    discordgo.PrimaryButton,
+		},
+	}
+	if request.series.ID != 0 {
+		buttons = append(buttons, discordgo.Button{
+			CustomID: "sonarrmissing:series",
+			Label:    "Search the whole series",
+			Style:    discordgo.SecondaryButton,
+		})
+	}
+
+	sent, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content:    "Trigger a search on Sonarr?",
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: buttons}},
+	})
+	if err != nil {
+		log.Println("Error sending Sonarr search buttons:", err)
+		return
+	}
+	sonarrSearchRequests.put(sent.ID, m.Author.ID, request)
+}
+
+// missingSeriesEpisodes returns the monitored episodes of a series that have aired without a file
+func missingSeriesEpisodes(s *discordgo.Session, channelID string, client sonarr.Service, series sonarr.Series) ([]sonarr.Episode, bool) {
+	episodes, err := client.Episodes(series.ID)
+	if err != nil {
+		s.ChannelMessageSend(channelID, arrErrorMessage("Sonarr", fmt.Sprintf("fetching the episodes of %s", series.Title), err))
+		return nil, false
+	}
+
+	now := time.Now()
+	var missing []sonarr.Episode
+	for _, episode := range episodes {
+		if episode.Monitored && !episode.HasFile && !episode.AirDateUtc.IsZero() && episode.AirDateUtc.Before(now) {
+			missing = append(missing, episode)
+		}
+	}
+
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].SeasonNumber != missing[j].SeasonNumber {
+			return missing[i].SeasonNumber < missing[j].SeasonNumber
+		}
+		return missing[i].EpisodeNumber < missing[j].EpisodeNumber
+	})
+	return missing, true
+}
+
+// handleSonarrMissingComponent runs the search chosen with the !sonarrmissing buttons and follows it until it completes
+func handleSonarrMissingComponent(s *discordgo.Session, i *discordgo.InteractionCreate, action string, values []string) {
+	request, ok := sonarrSearchRequests.get(s, i)
+	if !ok {
+		return
+	}
+
+	if !requireComponentPermission(s, i, PermissionTrusted, "trigger searches on Sonarr") {
+		return
+	}
+	sonarrSearchRequests.remove(i.Message.ID)
+
+	command := sonarr.NewCommand{Name: "EpisodeSearch", EpisodeIDs: request.episodeIDs}
+	description := fmt.Sprintf("%d episodes", len(request.episodeIDs))
+	if action == "series" {
+		command = sonarr.NewCommand{Name: "SeriesSearch", SeriesID: request.series.ID}
+		description = request.series.Title
+	}
+
+	respondUpdate(s, i, fmt.Sprintf("🔎 Queueing a search for %s...", description), nil)
+
+	queued, err := request.client.RunCommand(command)
+	if err != nil {
+		s.ChannelMessageEdit(i.ChannelID, i.Message.ID, arrErrorMessage("Sonarr", "queueing the search on Sonarr", err))
+		return
+	}
+	trackSonarrCommand(s, i.ChannelID, i.Message.ID, request.client, queued, fmt.Sprintf("Search for %s", description))
+}
@@ -0,0 +1,128 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/sonarr"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// handleSonarrMonitor responds to the !sonarrmonitor command, turning monitoring of a series or its seasons on or off
+func handleSonarrMonitor(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr monitor arguments:", args)
+
+	// The slash command passes the season as --season N or --season all
+	flags, args := splitFlags(args, "season")
+
+	if len(args) < 2 {
+		sendUsage(s, m.ChannelID, "sonarrmonitor")
+		return
+	}
+
+	var monitored bool
+	switch strings.ToLower(args[len(args)-1]) {
+	case "on":
+		monitored = true
+	case "off":
+		monitored = false
+	default:
+		sendUsage(s, m.ChannelID, "sonarrmonitor")
+		return
+	}
+	args = args[:len(args)-1]
+
+	// An optional "season N" or "all" comes between the series and the state
+	season := flags["season"]
+	if n := len(args); season == "" && n > 2 && strings.EqualFold(args[n-2], "season") {
+		season = args[n-1]
+		args = args[:n-2]
+	} else if season == "" && n > 1 && strings.EqualFold(args[n-1], "all") {
+		season = "all"
+		args = args[:n-1]
+	}
+
+	seasonNumber := -1
+	if season != "" && !strings.EqualFold(season, "all") {
+		var err error
+		seasonNumber, err = strconv.Atoi(season)
+		if err != nil || seasonNumber < 0 {
+			sendUsage(s, m.ChannelID, "sonarrmonitor")
+			return
+		}
+	}
+
+	if len(args) == 0 {
+		sendUsage(s, m.ChannelID, "sonarrmonitor")
+		return
+	}
+
+	series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args, " "))
+	if !ok {
+		return
+	}
+
+	update := sonarr.SeriesUpdate{}
+	switch {
+	case season == "":
+		update.Monitored = &monitored
+	case seasonNumber >= 0:
+		if !hasSeason(series, seasonNumber) {
+			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("**%s** has no season %d.", series.Title, seasonNumber))
+			return
+		}
+		update.SeasonMonitored = map[int]bool{seasonNumber: monitored}
+		// A monitored season of an unmonitored series is still ignored by Sonarr
+		if monitored {
+			update.Monitored = &monitored
+		}
+	default:
+		update.Monitored = &monitored
+		update.SeasonMonitored = make(map[int]bool)
+		for _, season := range series.Seasons {
+			update.SeasonMonitored[season.SeasonNumber] = monitored
+		}
+	}
+
+	updated, err := client.UpdateSeries(series.ID, update)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", fmt.Sprintf("updating %s on Sonarr", series.Title), err))
+		return
+	}
+	log.Printf("Sonarr monitoring of %s (%d) changed by %s", updated.Title, updated.ID, m.Author.Username)
+
+	s.ChannelMessageSend(m.ChannelID, monitorStateMessage(updated))
+}
+
+// hasSeason reports whether the series has a season with the given number
+func hasSeason(series sonarr.Series, number int) bool {
+	for _, season := range series.Seasons {
+		if season.SeasonNumber == number {
+			return true
+		}
+	}
+	return false
+}
+
+// monitorStateMessage describes whether a series is monitored and which of its seasons are
+func monitorStateMessage(series sonarr.Series) string {
+	var monitoredSeasons []string
+	for _, season := range series.Seasons {
+		if season.Monitored {
+			monitoredSeasons = append(monitoredSeasons, strconv.Itoa(season.SeasonNumber))
+		}
+	}
+
+	seasons := "none"
+	if len(monitoredSeasons) > 0 {
+		seasons = strings.Join(monitoredSeasons, ", ")
+	}
+
+	state := "unmonitored"
+	if series.Monitored {
+		state = "monitored"
+	}
+	return fmt.Sprintf("✅ **%s** is now %s.\nMonitored seasons: %s", series.Title, state, seasons)
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"main/sonarr"
+	"strings"
+	"testing"
+)
+
+func TestHandleSonarrMonitor(t *testing.T) {
+	tests := []struct {
+		name           string
+		startMonitored bool
+		args           string
+		wantUpdate     bool
+		wantMonitored  bool
+		wantSeasons    []bool // monitored state of seasons 1 and 2
+		wantMessage    string
+	}{
+		{"series off", true, "severance off", true, false, []bool{true, false},
+			"✅ **Severance** is now unmonitored.\nMonitored seasons: 1"},
+		{"season on", false, "severance season 2 on", true, true, []bool{true, true},
+			"✅ **Severance** is now monitored.\nMonitored seasons: 1, 2"},
+		{"season flag", false, "severance --season=2 on", true, true, []bool{true, true}, "Monitored seasons: 1, 2"},
+		{"all seasons off", true, "severance all off", true, false, []bool{false, false}, "Monitored seasons: none"},
+		{"missing season", false, "severance season 5 on", false, false, []bool{true, false}, "**Severance** has no season 5."},
+		{"no state", false, "severance", false, false, []bool{true, false}, "Usage: `!sonarrmonitor"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &fakeSonarr{series: []sonarr.Series{{
+				ID: 7, Title: "Severance", Year: 2022, Monitored: test.startMonitored,
+				Seasons: []sonarr.Season{{SeasonNumber: 1, Monitored: true}, {SeasonNumber: 2}},
+			}}}
+			s, sent := newTestSession(t)
+
+			handleSonarrMonitor(s, testMessage(), client, strings.Fields(test.args))
+
+			if test.wantUpdate != (len(client.updates) == 1) {
+				t.Errorf("%d updates sent to Sonarr, want an update: %v", len(client.updates), test.wantUpdate)
+			}
+			series := client.series[0]
+			if series.Monitored != test.wantMonitored {
+				t.Errorf("series monitored = %v, want %v", series.Monitored, test.wantMonitored)
+			}
+			for index, want := range test.wantSeasons {
+				if series.Seasons[index].Monitored != want {
+					t.Errorf("season %d monitored = %v, want %v", index+1, series.Seasons[index].Monitored, want)
+				}
+			}
+			if len(*sent) != 1 || !strings.Contains((*sent)[0], test.wantMessage) {
+				t.Errorf("sent %q, want a message with %q", *sent, test.wantMessage)
+			}
+		})
+	}
+}
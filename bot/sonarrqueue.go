@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"fmt"
+	"main/sonarr"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sonarrQueue is the Sonarr download queue shown by !sonarrqueue
+type sonarrQueue struct {
+	client sonarr.Service
+}
+
+// handleSonarrQueue responds to the !sonarrqueue command, listing the queue or removing an item from it
+func handleSonarrQueue(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	handleArrQueue(s, m, sonarrApp, sonarrQueue{client}, args)
+}
+
+// Items returns the first count items of the queue with their series and episode names
+func (q sonarrQueue) Items(count int) ([]arrQueueItem, int, error) {
+	queue, err := q.client.Queue(1, count)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var items []arrQueueItem
+	for _, record := range queue.Records {
+		// Prefer the series and episode names over the release title
+		title := record.Title
+		if record.Series != nil && record.Episode != nil {
+			title = fmt.Sprintf("%s %s - %s", record.Series.Title, record.Episode.Code(), record.Episode.Title)
+		}
+
+		item := arrQueueItem{
+			ID:                    record.ID,
+			Title:                 title,
+			Progress:              record.Progress(),
+			Size:                  int64(record.Size),
+			TimeLeft:              record.TimeLeft,
+			DownloadClient:        record.DownloadClient,
+			Status:                record.Status,
+			TrackedDownloadStatus: record.TrackedDownloadStatus,
+			ErrorMessage:          record.ErrorMessage,
+		}
+		for _, status := range record.StatusMessages {
+			item.Warnings = append(item.Warnings, status.Messages...)
+		}
+		items = append(items, item)
+	}
+	return items, queue.TotalRecords, nil
+}
+
+// Remove removes an item from the queue and the download client
+func (q sonarrQueue) Remove(id int, blocklist bool) error {
+	return q.client.RemoveFromQueue(id, blocklist)
+}
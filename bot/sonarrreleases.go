@@ -0,0 +1,212 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/format"
+	"main/sonarr"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// releaseLimit is the number of releases listed by !sonarrreleases, a select menu holds at most 25 options
+const releaseLimit = 20
+
+// episodeCodePattern matches an episode code such as S01E02
+var episodeCodePattern = regexp.MustCompile(`(?i)^s(\d{1,2})e(\d{1,3})$`)
+
+// sonarrReleaseRequest is the state of the grab menu sent by !sonarrreleases
+type sonarrReleaseRequest struct {
+	client   sonarr.Service
+	label    string // series title and episode code
+	releases []sonarr.Release
+}
+
+// sonarrReleaseRequests holds the pending grab menus by message ID
+var sonarrReleaseRequests = newSessionStore[*sonarrReleaseRequest](15 * time.Minute)
+
+// handleSonarrReleases responds to the !sonarrreleases command with the releases the indexers have for an
+// episode, in Sonarr's order of preference, followed by a menu to grab one of them.
+func handleSonarrReleases(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	log.Println("Sonarr releases arguments:", args)
+
+	if len(args) < 2 {
+		sendUsage(s, m.ChannelID, "sonarrreleases")
+		return
+	}
+
+	code := episodeCodePattern.FindStringSubmatch(args[len(args)-1])
+	if code == nil {
+		sendUsage(s, m.ChannelID, "sonarrreleases")
+		return
+	}
+	seasonNumber, _ := strconv.Atoi(code[1])
+	episodeNumber, _ := strconv.Atoi(code[2])
+
+	series, ok := resolveSeries(s, m.ChannelID, client, strings.Join(args[:len(args)-1], " "))
+	if !ok {
+		return
+	}
+
+	episodes, err := client.Episodes(series.ID)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching episodes from Sonarr", err))
+		return
+	}
+
+	var episode *sonarr.Episode
+	for index := range episodes {
+		if episodes[index].SeasonNumber == seasonNumber && episodes[index].EpisodeNumber == episodeNumber {
+			episode = &episodes[index]
+			break
+		}
+	}
+	if episode == nil {
+		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("**%s** has no episode S%02dE%02d.", series.Title, seasonNumber, episodeNumber))
+		return
+	}
+
+	label := fmt.Sprintf("%s %s", series.Title, episode.Code())
+	status, err := s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("🔎 Searching the indexers for **%s**...", label))
+	if err != nil {
+		log.Println("Error sending Sonarr releases message:", err)
+		return
+	}
+
+	releases, err := client.Releases(episode.ID)
+	if err != nil {
+		s.ChannelMessageEdit(m.ChannelID, status.ID, arrErrorMessage("Sonarr", fmt.Sprintf("searching releases for %s", label), err))
+		return
+	}
+
+	if len(releases) == 0 {
+		s.ChannelMessageEdit(m.ChannelID, status.ID, fmt.Sprintf("No releases found for **%s**.", label))
+		return
+	}
+
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].ReleaseWeight < releases[j].ReleaseWeight
+	})
+
+	header := fmt.Sprintf("Found %d releases for **%s**", len(releases), label)
+	if len(releases) > releaseLimit {
+		header += fmt.Sprintf(", showing the best %d", releaseLimit)
+		releases = releases[:releaseLimit]
+	}
+	s.ChannelMessageEdit(m.ChannelID, status.ID, header+":")
+
+	var message string
+	for index, release := range releases {
+		message += releaseLine(index+1, release)
+	}
+	sendMessageChunks(s, m.ChannelID, message)
+
+	request := &sonarrReleaseRequest{client: client, label: label, releases: releases}
+	menu, err := s.ChannelMessageSendComplex(m.ChannelID, &discordgo.MessageSend{
+		Content:    "Pick a release to grab:",
+		Components: request.components(),
+	})
+	if err != nil {
+		log.Println("Error sending Sonarr releases menu:", err)
+		return
+	}
+
+	sonarrReleaseRequests.put(menu.ID, m.Author.ID, request)
+}
+
+// handleSonarrReleasesComponent grabs the release picked in a !sonarrreleases menu
+func handleSonarrReleasesComponent(s *discordgo.Session, i *discordgo.InteractionCreate, action string, values []string) {
+	request, ok := sonarrReleaseRequests.get(s, i)
+	if !ok {
+		return
+	}
+
+	if action == "cancel" {
+		sonarrReleaseRequests.remove(i.Message.ID)
+		respondUpdate(s, i, "Grab cancelled.", nil)
+		return
+	}
+
+	if !requireComponentPermission(s, i, PermissionTrusted, "grab releases on Sonarr") {
+		return
+	}
+	sonarrReleaseRequests.remove(i.Message.ID)
+
+	index, _ := strconv.Atoi(values[0])
+	release := request.releases[index]
+
+	respondUpdate(s, i, fmt.Sprintf("📥 Grabbing `%s`...", release.Title), nil)
+
+	content := fmt.Sprintf("📥 Sent `%s` to the download client for **%s**.", release.Title, request.label)
+	if err := request.client.GrabRelease(release.GUID, release.IndexerID); err != nil {
+		content = arrErrorMessage("Sonarr", fmt.Sprintf("grabbing %s", release.Title), err)
+	} else {
+		log.Printf("Sonarr release %s grabbed by %s", release.Title, interactionUser(i).Username)
+	}
+
+	if _, err := s.ChannelMessageEdit(i.ChannelID, i.Message.ID, content); err != nil {
+		log.Println("Error editing Sonarr releases message:", err)
+	}
+}
+
+// releaseLine describes a numbered release with its details and, when Sonarr would not grab it, why
+func releaseLine(number int, release sonarr.Release) string {
+	icon := "✅"
+	if release.Rejected {
+		icon = "❌"
+	}
+
+	line := fmt.Sprintf("`%d` %s **%s**\n> %s\n", number, icon, release.Title, releaseDetails(release))
+	for _, rejection := range release.Rejections {
+		line += fmt.Sprintf("> - %s\n", rejection)
+	}
+	return line
+}
+
+// releaseDetails returns the quality, size, seeders, indexer and age of a release on one line
+func releaseDetails(release sonarr.Release) string {
+	details := []string{release.Quality.Name(), format.Bytes(release.Size)}
+	if release.Seeders != nil {
+		details = append(details, fmt.Sprintf("%d seeders", *release.Seeders))
+	}
+	details = append(details, release.Indexer, formatAge(release.AgeHours))
+	return strings.Join(details, " · ")
+}
+
+// formatAge formats the age of a release, in hours for the first day then in days
+func formatAge(hours float64) string {
+	if hours < 24 {
+		return fmt.Sprintf("%.0fh old", hours)
+	}
+	return fmt.Sprintf("%.0fd old", hours/24)
+}
+
+// components renders the release menu and the cancel button
+func (r *sonarrReleaseRequest) components() []discordgo.MessageComponent {
+	var options []discordgo.SelectMenuOption
+	for index, release := range r.releases {
+		prefix := "✅"
+		if release.Rejected {
+			prefix = "❌"
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       format.Truncate(fmt.Sprintf("%d. %s %s", index+1, prefix, release.Title), 100),
+			Value:       strconv.Itoa(index),
+			Description: format.Truncate(releaseDetails(release), 100),
+		})
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{CustomID: "sonarrreleases:grab", Placeholder: "Release to grab", Options: options},
+		}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{CustomID: "sonarrreleases:cancel", Label: "Cancel", Style: discordgo.SecondaryButton},
+		}},
+	}
+}
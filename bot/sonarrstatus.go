@@ -0,0 +1,300 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"main/auth"
+	"main/format"
+	"main/sonarr"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultMinFreeGB is the free space warning threshold used when min_free_gb is not set in the config file
+const defaultMinFreeGB = 50
+
+// statusConfig is the sonarr_status section of the config file, set by Init
+var statusConfig auth.StatusMonitorConfig
+
+// minFreeSpace returns the free space in bytes below which a root folder is reported
+func minFreeSpace() int64 {
+	gb := statusConfig.MinFreeGB
+	if gb <= 0 {
+		gb = defaultMinFreeGB
+	}
+	return int64(gb * (1 << 30))
+}
+
+// folderSpace is the free space of a root folder and the size of the disk it is on, zero when unknown
+type folderSpace struct {
+	path  string
+	free  int64
+	total int64
+}
+
+// low reports whether the folder has less free space than the threshold
+func (f folderSpace) low() bool {
+	return f.free < minFreeSpace()
+}
+
+// sonarrStatusReport gathers the version, health issues and root folder space of Sonarr
+type sonarrStatusReport struct {
+	status  sonarr.SystemStatus
+	health  []sonarr.HealthCheck
+	folders []folderSpace
+}
+
+// fetchSonarrStatus queries the system status, health checks, root folders and disk space of Sonarr
+func fetchSonarrStatus(client sonarr.Service) (sonarrStatusReport, error) {
+	var report sonarrStatusReport
+	var err error
+
+	if report.status, err = client.SystemStatus(); err != nil {
+		return report, err
+	}
+
+	checks, err := client.Health()
+	if err != nil {
+		return report, err
+	}
+	for _, check := range checks {
+		if check.Type != "ok" {
+			report.health = append(report.health, check)
+		}
+	}
+
+	rootFolders, err := client.RootFolders()
+	if err != nil {
+		return report, err
+	}
+	disks, err := client.DiskSpace()
+	if err != nil {
+		return report, err
+	}
+
+	// The disk a root folder is on is the one with the longest path the folder starts with
+	for _, folder := range rootFolders {
+		space := folderSpace{path: folder.Path, free: folder.FreeSpace}
+		longest := -1
+		for _, disk := range disks {
+			if strings.HasPrefix(folder.Path, disk.Path) && len(disk.Path) > longest {
+				longest = len(disk.Path)
+				space.total = disk.TotalSpace
+			}
+		}
+		report.folders = append(report.folders, space)
+	}
+
+	return report, nil
+}
+
+// handleSonarrStatus responds to the !sonarrstatus command with the version, health issues and free space of Sonarr
+func handleSonarrStatus(s *discordgo.Session, m *discordgo.MessageCreate, client sonarr.Service, args []string) {
+	report, err := fetchSonarrStatus(client)
+	if err != nil {
+		s.ChannelMessageSend(m.ChannelID, arrErrorMessage("Sonarr", "fetching the status of Sonarr", err))
+		return
+	}
+
+	s.ChannelMessageSendEmbed(m.ChannelID, report.embed())
+}
+
+// embed renders the report, coloured by the most severe problem found
+func (r sonarrStatusReport) embed() *discordgo.MessageEmbed {
+	var health string
+	var warning, failing bool
+	for _, check := range r.health {
+		if check.Type == "error" {
+			failing = true
+		} else {
+			warning = true
+		}
+		health += healthCheckLine(check) + "\n"
+	}
+	if health == "" {
+		health = "✅ No issues"
+	}
+
+	var disks string
+	for _, folder := range r.folders {
+		warning = warning || folder.low()
+		disks += folderSpaceLine(folder) + "\n"
+	}
+	if disks == "" {
+		disks = "No root folders"
+	}
+
+	color := 0x2ecc71 // green
+	if failing {
+		color = 0xe74c3c // red
+	} else if warning {
+		color = 0xf39c12 // orange
+	}
+
+	title := r.status.AppName
+	if title == "" {
+		title = "Sonarr"
+	}
+
+	// Discord rejects embeds with an empty field
+	version, branch := r.status.Version, r.status.Branch
+	if version == "" {
+		version = "unknown"
+	}
+	if branch == "" {
+		branch = "unknown"
+	}
+	// Sonarr v3 and some proxies leave the start time out
+	uptime := "unknown"
+	if !r.status.StartTime.IsZero() {
+		uptime = formatUptime(time.Since(r.status.StartTime))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: title + " status",
+		Color: color,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Version", Value: version, Inline: true},
+			{Name: "Branch", Value: branch, Inline: true},
+			{Name: "Uptime", Value: uptime, Inline: true},
+			{Name: "Health", Value: format.Truncate(health, 1024)},
+			{Name: fmt.Sprintf("Root folders (warning below %s free)", format.Bytes(minFreeSpace())), Value: format.Truncate(disks, 1024)},
+		},
+	}
+}
+
+// healthCheckLine describes a health issue with a link to its wiki page
+func healthCheckLine(check sonarr.HealthCheck) string {
+	icon := "⚠️"
+	if check.Type == "error" {
+		icon = "❌"
+	} else if check.Type == "notice" {
+		icon = "ℹ️"
+	}
+
+	line := fmt.Sprintf("%s %s", icon, check.Message)
+	if check.WikiURL != "" {
+		line += fmt.Sprintf(" ([wiki](%s))", check.WikiURL)
+	}
+	return line
+}
+
+// folderSpaceLine describes the free space of a root folder, flagged when below the threshold
+func folderSpaceLine(folder folderSpace) string {
+	icon := "💾"
+	if folder.low() {
+		icon = "⚠️"
+	}
+
+	line := fmt.Sprintf("%s `%s` %s free", icon, folder.path, format.Bytes(folder.free))
+	if folder.total > 0 {
+		line += fmt.Sprintf(" of %s (%.0f%%)", format.Bytes(folder.total), float64(folder.free)/float64(folder.total)*100)
+	}
+	return line
+}
+
+// formatUptime formats a duration as days, hours and minutes, e.g. "3d 4h 12m"
+func formatUptime(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	return fmt.Sprintf("%dh %dm", hours, minutes)
+}
+
+// statusMonitor remembers the health issues and low root folders of an instance already reported by the periodic check
+type statusMonitor struct {
+	name       string // instance name, prefixed to the alerts when several instances are configured
+	client     sonarr.Service
+	issues     map[string]sonarr.HealthCheck // keyed by source and message
+	lowFolders map[string]folderSpace        // keyed by path
+}
+
+// startSonarrStatusMonitor runs the status checks of every instance every interval_minutes when a channel is configured
+func startSonarrStatusMonitor(s *discordgo.Session) {
+	if statusConfig.Channel == "" || statusConfig.IntervalMinutes <= 0 || len(sonarrInstances) == 0 {
+		return
+	}
+
+	for _, instance := range sonarrInstances {
+		monitor := &statusMonitor{
+			name:       instance.name,
+			client:     instance.client,
+			issues:     make(map[string]sonarr.HealthCheck),
+			lowFolders: make(map[string]folderSpace),
+		}
+
+		go func() {
+			ticker := time.NewTicker(time.Duration(statusConfig.IntervalMinutes) * time.Minute)
+			defer ticker.Stop()
+			for {
+				monitor.check(s)
+				<-ticker.C
+			}
+		}()
+	}
+	log.Printf("Sonarr status monitor started for %d instances, checking every %d minutes", len(sonarrInstances), statusConfig.IntervalMinutes)
+}
+
+// check fetches the status of the instance and posts the changes since the previous check
+func (sm *statusMonitor) check(s *discordgo.Session) {
+	report, err := fetchSonarrStatus(sm.client)
+	if err != nil {
+		log.Printf("Error checking the status of Sonarr instance %s: %v", sm.name, err)
+		return
+	}
+
+	alerts := sm.update(report)
+	if len(alerts) == 0 {
+		return
+	}
+	if len(sonarrInstances) > 1 {
+		for index := range alerts {
+			alerts[index] = fmt.Sprintf("[%s] %s", sm.name, alerts[index])
+		}
+	}
+	sendMessageChunks(s, statusConfig.Channel, strings.Join(alerts, "\n"))
+}
+
+// update records the issues and low folders of a report and returns the alerts for what changed:
+// new health issues, resolved ones, folders dropping below the threshold and folders recovering.
+func (sm *statusMonitor) update(report sonarrStatusReport) []string {
+	var alerts []string
+
+	current := make(map[string]sonarr.HealthCheck)
+	for _, check := range report.health {
+		key := check.Source + "|" + check.Message
+		current[key] = check
+		if _, known := sm.issues[key]; !known {
+			alerts = append(alerts, "**New Sonarr health issue:** "+healthCheckLine(check))
+		}
+	}
+	for key, check := range sm.issues {
+		if _, ok := current[key]; !ok {
+			alerts = append(alerts, "✅ **Sonarr health issue resolved:** "+check.Message)
+		}
+	}
+	sm.issues = current
+
+	lowFolders := make(map[string]folderSpace)
+	for _, folder := range report.folders {
+		if !folder.low() {
+			if _, wasLow := sm.lowFolders[folder.path]; wasLow {
+				alerts = append(alerts, fmt.Sprintf("✅ **Disk space recovered:** `%s` has %s free", folder.path, format.Bytes(folder.free)))
+			}
+			continue
+		}
+		lowFolders[folder.path] = folder
+		if _, wasLow := sm.lowFolders[folder.path]; !wasLow {
+			alerts = append(alerts, "**Low disk space:** "+folderSpaceLine(folder))
+		}
+	}
+	sm.lowFolders = lowFolders
+
+	return alerts
+}
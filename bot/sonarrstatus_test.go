@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"main/sonarr"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStatusMonitorUpdate(t *testing.T) {
+	const gb = 1 << 30
+	indexerDown := sonarr.HealthCheck{Source: "IndexerStatusCheck", Type: "warning", Message: "Indexers unavailable: NZBgeek"}
+	noClient := sonarr.HealthCheck{Source: "DownloadClientCheck", Type: "error", Message: "No download client is available"}
+
+	// Each step is a check of the same instance, with the alerts it must post
+	steps := []struct {
+		name    string
+		report  sonarrStatusReport
+		wantNew []string
+	}{
+		{
+			name:   "healthy",
+			report: sonarrStatusReport{folders: []folderSpace{{path: "/tv", free: 500 * gb}}},
+		},
+		{
+			name: "new issue and low folder",
+			report: sonarrStatusReport{
+				health:  []sonarr.HealthCheck{indexerDown},
+				folders: []folderSpace{{path: "/tv", free: 10 * gb}},
+			},
+			wantNew: []string{
+				"**New Sonarr health issue:** ⚠️ Indexers unavailable: NZBgeek",
+				"**Low disk space:** ⚠️ `/tv` 10.0 GB free",
+			},
+		},
+		{
+			name: "nothing changed",
+			report: sonarrStatusReport{
+				health:  []sonarr.HealthCheck{indexerDown},
+				folders: []folderSpace{{path: "/tv", free: 9 * gb}},
+			},
+		},
+		{
+			name: "one issue replaced by another",
+			report: sonarrStatusReport{
+				health:  []sonarr.HealthCheck{noClient},
+				folders: []folderSpace{{path: "/tv", free: 9 * gb}},
+			},
+			wantNew: []string{
+				"**New Sonarr health issue:** ❌ No download client is available",
+				"✅ **Sonarr health issue resolved:** Indexers unavailable: NZBgeek",
+			},
+		},
+		{
+			name:   "recovered",
+			report: sonarrStatusReport{folders: []folderSpace{{path: "/tv", free: 80 * gb}}},
+			wantNew: []string{
+				"✅ **Sonarr health issue resolved:** No download client is available",
+				"✅ **Disk space recovered:** `/tv` has 80.0 GB free",
+			},
+		},
+		{
+			name:   "still healthy",
+			report: sonarrStatusReport{folders: []folderSpace{{path: "/tv", free: 80 * gb}}},
+		},
+	}
+
+	monitor := &statusMonitor{
+		issues:     make(map[string]sonarr.HealthCheck),
+		lowFolders: make(map[string]folderSpace),
+	}
+	for _, step := range steps {
+		alerts := monitor.update(step.report)
+		// The resolved issues come from a map, only the set of alerts matters
+		slices.Sort(alerts)
+		slices.Sort(step.wantNew)
+		if !slices.Equal(alerts, step.wantNew) {
+			t.Errorf("%s: alerts = %q, want %q", step.name, alerts, step.wantNew)
+		}
+	}
+}
+
+func TestStatusEmbedUnknownFields(t *testing.T) {
+	fields := make(map[string]string)
+	for _, field := range (sonarrStatusReport{}).embed().Fields {
+		if field.Value == "" {
+			t.Errorf("field %q is empty, Discord rejects the embed", field.Name)
+		}
+		fields[field.Name] = field.Value
+	}
+	for _, name := range []string{"Version", "Branch", "Uptime"} {
+		if fields[name] != "unknown" {
+			t.Errorf("%s = %q, want unknown", name, fields[name])
+		}
+	}
+
+	report := sonarrStatusReport{status: sonarr.SystemStatus{StartTime: time.Now().Add(-26*time.Hour - 5*time.Minute)}}
+	for _, field := range report.embed().Fields {
+		if field.Name == "Uptime" && field.Value != "1d 2h 5m" {
+			t.Errorf("Uptime = %q, want 1d 2h 5m", field.Value)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"log"
+	"main/auth"
+	"main/webhook"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// startWebhookServer starts the listener receiving the *arr webhook events when one is configured
+func startWebhookServer(s *discordgo.Session) {
+	config, err := auth.LoadConfig()
+	if err != nil {
+		log.Println("Error loading config file:", err)
+		return
+	}
+	if config.Webhook.Listen == "" {
+		return
+	}
+
+	creds, err := auth.LoadCreds()
+	if err != nil {
+		log.Println("Error loading credentials:", err)
+		return
+	}
+	if creds.WebhookSecret == "" {
+		log.Println("Webhook listener not started: webhook_secret is not set in the credentials file")
+		return
+	}
+
+	server := webhook.NewServer(config.Webhook, creds.WebhookSecret, func(channelID string, embed *discordgo.MessageEmbed) error {
+		_, err := s.ChannelMessageSendEmbed(channelID, embed)
+		return err
+	})
+
+	// When a series is added, deleted or its files change the cached series list of the instance that sent
+	// the event is out of date
+	server.OnSonarrEvent(func(event webhook.SonarrEvent) {
+		switch event.Kind() {
+		case "download", "upgrade", "rename", "seriesadd", "seriesdelete", "episodefiledelete":
+			for _, instance := range webhookSonarrInstances(event.InstanceName) {
+				instance.client.Invalidate()
+			}
+		}
+	})
+
+	// Likewise for the cached movie list when a movie is added, deleted or its files change
+	server.OnRadarrEvent(func(event webhook.RadarrEvent) {
+		switch event.Kind() {
+		case "download", "upgrade", "rename", "movieadded", "moviedelete", "moviefiledelete":
+			if radarrClient != nil {
+				radarrClient.Invalidate()
+			}
+		}
+	})
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil {
+			log.Println("Webhook listener stopped:", err)
+		}
+	}()
+}
+
+// webhookSonarrInstances returns the instance named like the Sonarr instance that sent an event, set in
+// Sonarr under Settings -> General. Every instance is returned when none has that name, as the event may
+// come from any of them.
+func webhookSonarrInstances(instanceName string) []sonarrInstance {
+	for _, instance := range sonarrInstances {
+		if instanceName != "" && strings.EqualFold(instance.name, strings.TrimSpace(instanceName)) {
+			return []sonarrInstance{instance}
+		}
+	}
+	return sonarrInstances
+}
@@ -0,0 +1,33 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWebhookSonarrInstances(t *testing.T) {
+	saved := sonarrInstances
+	t.Cleanup(func() { sonarrInstances = saved })
+	sonarrInstances = []sonarrInstance{{name: "default"}, {name: "anime"}}
+
+	tests := []struct {
+		instanceName string
+		want         []string
+	}{
+		{"anime", []string{"anime"}},
+		{"Anime", []string{"anime"}},
+		{"default", []string{"default"}},
+		{"Sonarr", []string{"default", "anime"}},
+		{"", []string{"default", "anime"}},
+	}
+
+	for _, test := range tests {
+		var names []string
+		for _, instance := range webhookSonarrInstances(test.instanceName) {
+			names = append(names, instance.name)
+		}
+		if !slices.Equal(names, test.want) {
+			t.Errorf("webhookSonarrInstances(%q) = %v, want %v", test.instanceName, names, test.want)
+		}
+	}
+}
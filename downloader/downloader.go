@@ -0,0 +1,85 @@
+// Package downloader talks to the download clients the *arr apps hand their releases to,
+// qBittorrent through its Web API and SABnzbd through its API
+package downloader
+
+import (
+	"fmt"
+	"main/api"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AllTransfers is the ID that makes Pause and Resume act on every transfer, the client's global pause
+const AllTransfers = "all"
+
+// Client is a download client the bot can report on and control.
+// It is implemented by QBittorrent and SABnzbd, handlers depend on the interface so they can be run against a fake.
+type Client interface {
+	// Name returns the name of the client shown in Discord, e.g. qBittorrent
+	Name() string
+	// Status returns the global speeds, the free space and the unfinished transfers
+	Status() (Status, error)
+	// Pause pauses a transfer, or every transfer when id is AllTransfers
+	Pause(id string) error
+	// Resume resumes a transfer, or every transfer when id is AllTransfers
+	Resume(id string) error
+	// Delete removes a transfer, optionally deleting the downloaded files
+	Delete(id string, deleteFiles bool) error
+	// SetSlowMode turns the client's reduced speed limit on or off
+	SetSlowMode(on bool) error
+}
+
+// Status is the state of a download client
+type Status struct {
+	DownloadSpeed int64 // bytes per second
+	UploadSpeed   int64 // bytes per second, always zero for usenet
+	FreeSpace     int64 // bytes free on the download disk, -1 when unknown
+	Paused        bool  // every transfer is paused
+	SlowMode      bool  // the reduced speed limit is on
+	Transfers     []Transfer
+}
+
+// Transfer is a download in progress, queued or paused
+type Transfer struct {
+	ID            string // torrent hash or SABnzbd job ID
+	Name          string
+	State         string        // as reported by the client, e.g. downloading, stalledDL, Queued
+	Progress      float64       // percentage
+	Size          int64         // bytes
+	DownloadSpeed int64         // bytes per second, zero when the client only reports a global speed
+	ETA           time.Duration // zero when unknown
+	Paused        bool
+}
+
+// checkResponse returns an api.StatusError, so callers can use errors.Is with the api errors, when the
+// response status is not 2xx. Only the path of the URL is kept, SABnzbd carries its API key in the query.
+func checkResponse(resp *http.Response, body []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	message := strings.TrimSpace(string(body))
+	if len(message) > 200 {
+		message = message[:200]
+	}
+	return &api.StatusError{
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.Path,
+		StatusCode: resp.StatusCode,
+		Message:    message,
+	}
+}
+
+// unknownTransfer is returned when a transfer ID is not known to the client
+func unknownTransfer(client, id string) error {
+	return fmt.Errorf("%s has no transfer %s: %w", client, id, api.ErrNotFound)
+}
+
+// ShortID returns the start of the transfer ID, enough to tell transfers apart when typing it in a command
+func (t Transfer) ShortID() string {
+	id := strings.TrimPrefix(t.ID, "SABnzbd_nzo_")
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return id
+}
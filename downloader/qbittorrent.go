@@ -0,0 +1,254 @@
+package downloader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"main/api"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// qbittorrentNoETA is the ETA qBittorrent reports when it cannot estimate one
+const qbittorrentNoETA = 8640000
+
+// QBittorrent talks to the qBittorrent Web API v2. It logs in with the username and password on the first
+// request and again whenever the session cookie expires.
+type QBittorrent struct {
+	baseURL    string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu  sync.Mutex
+	sid string // session cookie, empty until logged in
+}
+
+// NewQBittorrent creates a qBittorrent client for the Web UI at baseURL, e.g. http://10.23.0.3:8080
+func NewQBittorrent(baseURL, username, password string, httpClient *http.Client) *QBittorrent {
+	return &QBittorrent{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		username:   username,
+		password:   password,
+		httpClient: httpClient,
+	}
+}
+
+// qbittorrentMainData is the part of /sync/maindata the client reads
+type qbittorrentMainData struct {
+	ServerState struct {
+		DownloadSpeed     int64 `json:"dl_info_speed"`
+		UploadSpeed       int64 `json:"up_info_speed"`
+		FreeSpace         int64 `json:"free_space_on_disk"`
+		UseAltSpeedLimits bool  `json:"use_alt_speed_limits"`
+	} `json:"server_state"`
+	Torrents map[string]qbittorrentTorrent `json:"torrents"` // keyed by hash
+}
+
+// qbittorrentTorrent is a torrent as listed by /sync/maindata
+type qbittorrentTorrent struct {
+	Name          string  `json:"name"`
+	State         string  `json:"state"`
+	Progress      float64 `json:"progress"` // 0 to 1
+	Size          int64   `json:"size"`
+	DownloadSpeed int64   `json:"dlspeed"`
+	ETA           int64   `json:"eta"` // seconds
+}
+
+// Name returns qBittorrent
+func (q *QBittorrent) Name() string {
+	return "qBittorrent"
+}
+
+// Status returns the global speeds, the free space and the torrents still downloading
+func (q *QBittorrent) Status() (Status, error) {
+	var data qbittorrentMainData
+	if err := q.get("/api/v2/sync/maindata", nil, &data); err != nil {
+		return Status{}, err
+	}
+
+	status := Status{
+		DownloadSpeed: data.ServerState.DownloadSpeed,
+		UploadSpeed:   data.ServerState.UploadSpeed,
+		FreeSpace:     data.ServerState.FreeSpace,
+		SlowMode:      data.ServerState.UseAltSpeedLimits,
+		Paused:        len(data.Torrents) > 0,
+	}
+
+	for hash, torrent := range data.Torrents {
+		paused := strings.HasPrefix(torrent.State, "paused") || strings.HasPrefix(torrent.State, "stopped")
+		if !paused {
+			status.Paused = false
+		}
+		// Finished torrents are seeding, only the unfinished ones are transfers worth listing
+		if torrent.Progress >= 1 {
+			continue
+		}
+
+		transfer := Transfer{
+			ID:            hash,
+			Name:          torrent.Name,
+			State:         torrent.State,
+			Progress:      torrent.Progress * 100,
+			Size:          torrent.Size,
+			DownloadSpeed: torrent.DownloadSpeed,
+			Paused:        paused,
+		}
+		if torrent.ETA > 0 && torrent.ETA < qbittorrentNoETA {
+			transfer.ETA = time.Duration(torrent.ETA) * time.Second
+		}
+		status.Transfers = append(status.Transfers, transfer)
+	}
+
+	// The torrents come in a map, sort them so the list does not move around between calls
+	sort.Slice(status.Transfers, func(i, j int) bool {
+		return status.Transfers[i].Name < status.Transfers[j].Name
+	})
+	return status, nil
+}
+
+// Pause pauses a torrent, or every torrent when id is AllTransfers
+func (q *QBittorrent) Pause(id string) error {
+	// qBittorrent 5 renamed pause to stop
+	return q.postFallback("/api/v2/torrents/pause", "/api/v2/torrents/stop", url.Values{"hashes": {id}})
+}
+
+// Resume resumes a torrent, or every torrent when id is AllTransfers
+func (q *QBittorrent) Resume(id string) error {
+	// qBittorrent 5 renamed resume to start
+	return q.postFallback("/api/v2/torrents/resume", "/api/v2/torrents/start", url.Values{"hashes": {id}})
+}
+
+// Delete removes a torrent, optionally deleting the downloaded files
+func (q *QBittorrent) Delete(id string, deleteFiles bool) error {
+	form := url.Values{"hashes": {id}, "deleteFiles": {fmt.Sprint(deleteFiles)}}
+	return q.post("/api/v2/torrents/delete", form)
+}
+
+// SetSlowMode turns the alternative speed limits, set in qBittorrent, on or off
+func (q *QBittorrent) SetSlowMode(on bool) error {
+	var mode int
+	if err := q.get("/api/v2/transfer/speedLimitsMode", nil, &mode); err != nil {
+		return err
+	}
+	// The API can only toggle the mode
+	if (mode == 1) == on {
+		return nil
+	}
+	return q.post("/api/v2/transfer/toggleSpeedLimitsMode", nil)
+}
+
+// get performs a GET request and decodes the JSON response into out
+func (q *QBittorrent) get(path string, query url.Values, out any) error {
+	u := q.baseURL + path
+	if query != nil {
+		u += "?" + query.Encode()
+	}
+
+	body, err := q.do(func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, u, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error unmarshalling qBittorrent response: %w", err)
+	}
+	return nil
+}
+
+// post sends a form to the API, the response is ignored
+func (q *QBittorrent) post(path string, form url.Values) error {
+	_, err := q.do(func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, q.baseURL+path, strings.NewReader(form.Encode()))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		return req, err
+	})
+	return err
+}
+
+// postFallback posts the form to path, or to fallback when the running version does not know path
+func (q *QBittorrent) postFallback(path, fallback string, form url.Values) error {
+	err := q.post(path, form)
+	if errors.Is(err, api.ErrNotFound) {
+		return q.post(fallback, form)
+	}
+	return err
+}
+
+// do performs the request built by newRequest with the session cookie, logging in first when there is no
+// session and once more when the session has expired, and returns the response body
+func (q *QBittorrent) do(newRequest func() (*http.Request, error)) ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.sid == "" {
+		if err := q.login(); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("error creating request: %w", err)
+		}
+		req.AddCookie(&http.Cookie{Name: "SID", Value: q.sid})
+
+		resp, err := q.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error performing request: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading response body: %w", err)
+		}
+
+		// qBittorrent answers 403 once the session has expired
+		if resp.StatusCode == http.StatusForbidden && attempt == 1 {
+			if err := q.login(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := checkResponse(resp, body); err != nil {
+			return nil, err
+		}
+		return body, nil
+	}
+}
+
+// login opens a session and keeps its cookie, the caller holds q.mu
+func (q *QBittorrent) login() error {
+	form := url.Values{"username": {q.username}, "password": {q.password}}
+	resp, err := q.httpClient.PostForm(q.baseURL+"/api/v2/auth/login", form)
+	if err != nil {
+		return fmt.Errorf("error logging in to qBittorrent: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if err := checkResponse(resp, body); err != nil {
+		return err
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == "SID" {
+			q.sid = cookie.Value
+			return nil
+		}
+	}
+	// Wrong credentials are answered with 200 "Fails." and no cookie
+	return fmt.Errorf("error logging in to qBittorrent: %w", api.ErrUnauthorized)
+}
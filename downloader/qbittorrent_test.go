@@ -0,0 +1,168 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"main/api"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// fakeQBittorrent is a stand-in for the qBittorrent Web API. It hands out a new session cookie on each login
+// and answers 403 to requests carrying any other cookie.
+type fakeQBittorrent struct {
+	password string
+	sid      string // current session cookie
+	logins   int
+	paths    []string // paths of the API requests after login
+	stopOnly bool     // answer 404 to pause and resume like qBittorrent 5
+}
+
+// handler serves the fake API
+func (f *fakeQBittorrent) handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/auth/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("username") != "admin" || r.FormValue("password") != f.password {
+			w.Write([]byte("Fails."))
+			return
+		}
+		f.logins++
+		f.sid = fmt.Sprintf("session%d", f.logins)
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: f.sid})
+		w.Write([]byte("Ok."))
+	})
+	mux.HandleFunc("/api/v2/", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("SID")
+		if err != nil || cookie.Value != f.sid {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		f.paths = append(f.paths, r.URL.Path)
+
+		switch r.URL.Path {
+		case "/api/v2/sync/maindata":
+			w.Write([]byte(`{
+				"server_state": {"dl_info_speed": 2048, "up_info_speed": 512, "free_space_on_disk": 1000000, "use_alt_speed_limits": true},
+				"torrents": {
+					"bbbb": {"name": "B", "state": "downloading", "progress": 0.5, "size": 100, "dlspeed": 10, "eta": 60},
+					"aaaa": {"name": "A", "state": "stalledDL", "progress": 0.1, "size": 100, "eta": 8640000},
+					"cccc": {"name": "C", "state": "uploading", "progress": 1}
+				}
+			}`))
+		case "/api/v2/torrents/pause", "/api/v2/torrents/resume":
+			if f.stopOnly {
+				http.NotFound(w, r)
+			}
+		case "/api/v2/torrents/stop", "/api/v2/torrents/start":
+			if !f.stopOnly {
+				http.NotFound(w, r)
+			}
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+	return mux
+}
+
+// newTestQBittorrent starts the fake API and returns a client logging in to it with password
+func newTestQBittorrent(t *testing.T, fake *fakeQBittorrent, password string) *QBittorrent {
+	server := httptest.NewServer(fake.handler(t))
+	t.Cleanup(server.Close)
+	return NewQBittorrent(server.URL, "admin", password, server.Client())
+}
+
+func TestQBittorrentStatus(t *testing.T) {
+	fake := &fakeQBittorrent{password: "secret"}
+	client := newTestQBittorrent(t, fake, "secret")
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if fake.logins != 1 {
+		t.Errorf("logins = %d, want 1", fake.logins)
+	}
+	if status.DownloadSpeed != 2048 || status.UploadSpeed != 512 || status.FreeSpace != 1000000 || !status.SlowMode {
+		t.Errorf("Status() = %+v, want the server state", status)
+	}
+
+	// The finished torrent is left out and the others are sorted by name
+	if len(status.Transfers) != 2 || status.Transfers[0].Name != "A" || status.Transfers[1].Name != "B" {
+		t.Fatalf("Transfers = %+v, want A and B", status.Transfers)
+	}
+	if status.Transfers[0].ETA != 0 {
+		t.Errorf("ETA of A = %v, want 0 for the unknown ETA", status.Transfers[0].ETA)
+	}
+	if status.Transfers[1].Progress != 50 {
+		t.Errorf("Progress of B = %v, want 50", status.Transfers[1].Progress)
+	}
+}
+
+func TestQBittorrentLoginAgainWhenSessionExpires(t *testing.T) {
+	fake := &fakeQBittorrent{password: "secret"}
+	client := newTestQBittorrent(t, fake, "secret")
+
+	if _, err := client.Status(); err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+
+	// Expire the session, the next request is answered 403 and the client logs in again
+	fake.sid = "expired"
+	if _, err := client.Status(); err != nil {
+		t.Fatalf("Status() after the session expired error = %v", err)
+	}
+	if fake.logins != 2 {
+		t.Errorf("logins = %d, want 2", fake.logins)
+	}
+	if client.sid != fake.sid {
+		t.Errorf("client session = %q, want the new cookie %q", client.sid, fake.sid)
+	}
+}
+
+func TestQBittorrentWrongPassword(t *testing.T) {
+	fake := &fakeQBittorrent{password: "secret"}
+	client := newTestQBittorrent(t, fake, "wrong")
+
+	_, err := client.Status()
+	if !errors.Is(err, api.ErrUnauthorized) {
+		t.Errorf("Status() error = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestQBittorrentPauseFallsBackToStop(t *testing.T) {
+	tests := []struct {
+		name     string
+		stopOnly bool
+		pause    bool
+		want     []string
+	}{
+		{"pause", false, true, []string{"/api/v2/torrents/pause"}},
+		{"resume", false, false, []string{"/api/v2/torrents/resume"}},
+		{"stop on qBittorrent 5", true, true, []string{"/api/v2/torrents/pause", "/api/v2/torrents/stop"}},
+		{"start on qBittorrent 5", true, false, []string{"/api/v2/torrents/resume", "/api/v2/torrents/start"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := &fakeQBittorrent{password: "secret", stopOnly: test.stopOnly}
+			client := newTestQBittorrent(t, fake, "secret")
+
+			var err error
+			if test.pause {
+				err = client.Pause(AllTransfers)
+			} else {
+				err = client.Resume(AllTransfers)
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			if !slices.Equal(fake.paths, test.want) {
+				t.Errorf("requests = %v, want %v", fake.paths, test.want)
+			}
+		})
+	}
+}
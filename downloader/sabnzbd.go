@@ -0,0 +1,227 @@
+package downloader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"main/api"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sabnzbdNoLimit is the speed limit, in percent of the configured line speed, that lifts the limit
+const sabnzbdNoLimit = "100"
+
+// SABnzbd talks to the SABnzbd API, authenticated by the API key in the query string
+type SABnzbd struct {
+	baseURL    string
+	apiKey     string
+	slowLimit  string // speed limit set by SetSlowMode, e.g. "20" percent or "2M"
+	httpClient *http.Client
+}
+
+// NewSABnzbd creates a SABnzbd client for the Web UI at baseURL, e.g. http://10.23.0.3:8085. slowLimit is the
+// speed limit SetSlowMode turns on, a percentage of the line speed set in SABnzbd or an absolute value such as 2M.
+func NewSABnzbd(baseURL, apiKey, slowLimit string, httpClient *http.Client) *SABnzbd {
+	return &SABnzbd{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		slowLimit:  slowLimit,
+		httpClient: httpClient,
+	}
+}
+
+// sabnzbdQueue is the part of the queue response the client reads, SABnzbd sends most numbers as strings
+type sabnzbdQueue struct {
+	Queue struct {
+		Paused     bool          `json:"paused"`
+		KBPerSec   string        `json:"kbpersec"`
+		DiskSpace1 string        `json:"diskspace1"` // GB free in the incomplete folder
+		DiskSpace2 string        `json:"diskspace2"` // GB free in the complete folder
+		SpeedLimit string        `json:"speedlimit"` // percent of the line speed
+		Slots      []sabnzbdSlot `json:"slots"`
+	} `json:"queue"`
+}
+
+// sabnzbdSlot is a job in the queue
+type sabnzbdSlot struct {
+	ID         string `json:"nzo_id"`
+	Filename   string `json:"filename"`
+	Status     string `json:"status"`
+	Percentage string `json:"percentage"`
+	MB         string `json:"mb"`
+	TimeLeft   string `json:"timeleft"` // e.g. 0:12:34 or 1:02:12:34 with days
+}
+
+// sabnzbdResult is the answer to the commands, status is false and error set when the command failed
+type sabnzbdResult struct {
+	Status *bool    `json:"status"`
+	Error  string   `json:"error"`
+	IDs    []string `json:"nzo_ids"` // jobs the command applied to
+}
+
+// Name returns SABnzbd
+func (c *SABnzbd) Name() string {
+	return "SABnzbd"
+}
+
+// Status returns the download speed, the free space and the jobs in the queue
+func (c *SABnzbd) Status() (Status, error) {
+	var data sabnzbdQueue
+	if err := c.get(url.Values{"mode": {"queue"}}, &data); err != nil {
+		return Status{}, err
+	}
+	queue := data.Queue
+
+	status := Status{
+		DownloadSpeed: int64(parseFloat(queue.KBPerSec) * 1024),
+		FreeSpace:     -1,
+		Paused:        queue.Paused,
+	}
+	if limit := parseFloat(queue.SpeedLimit); limit > 0 && limit < 100 {
+		status.SlowMode = true
+	}
+	// Downloads fill the incomplete folder and then the complete one, the fuller of the two runs out first
+	for _, free := range []string{queue.DiskSpace1, queue.DiskSpace2} {
+		if free == "" {
+			continue
+		}
+		bytes := int64(parseFloat(free) * (1 << 30))
+		if status.FreeSpace < 0 || bytes < status.FreeSpace {
+			status.FreeSpace = bytes
+		}
+	}
+
+	for _, slot := range queue.Slots {
+		status.Transfers = append(status.Transfers, Transfer{
+			ID:       slot.ID,
+			Name:     slot.Filename,
+			State:    slot.Status,
+			Progress: parseFloat(slot.Percentage),
+			Size:     int64(parseFloat(slot.MB) * (1 << 20)),
+			ETA:      parseTimeLeft(slot.TimeLeft),
+			Paused:   slot.Status == "Paused",
+		})
+	}
+	return status, nil
+}
+
+// Pause pauses a job, or the whole queue when id is AllTransfers
+func (c *SABnzbd) Pause(id string) error {
+	if id == AllTransfers {
+		return c.command(url.Values{"mode": {"pause"}})
+	}
+	return c.jobCommand(id, url.Values{"mode": {"queue"}, "name": {"pause"}, "value": {id}})
+}
+
+// Resume resumes a job, or the whole queue when id is AllTransfers
+func (c *SABnzbd) Resume(id string) error {
+	if id == AllTransfers {
+		return c.command(url.Values{"mode": {"resume"}})
+	}
+	return c.jobCommand(id, url.Values{"mode": {"queue"}, "name": {"resume"}, "value": {id}})
+}
+
+// Delete removes a job from the queue, optionally deleting the downloaded files
+func (c *SABnzbd) Delete(id string, deleteFiles bool) error {
+	query := url.Values{"mode": {"queue"}, "name": {"delete"}, "value": {id}}
+	if deleteFiles {
+		query.Set("del_files", "1")
+	}
+	return c.jobCommand(id, query)
+}
+
+// SetSlowMode sets the speed limit to the slow limit, or lifts it
+func (c *SABnzbd) SetSlowMode(on bool) error {
+	limit := sabnzbdNoLimit
+	if on {
+		limit = c.slowLimit
+	}
+	return c.command(url.Values{"mode": {"config"}, "name": {"speedlimit"}, "value": {limit}})
+}
+
+// command runs a command and checks SABnzbd accepted it
+func (c *SABnzbd) command(query url.Values) error {
+	var result sabnzbdResult
+	return c.get(query, &result)
+}
+
+// jobCommand runs a command on a job and checks it applied to that job
+func (c *SABnzbd) jobCommand(id string, query url.Values) error {
+	var result sabnzbdResult
+	if err := c.get(query, &result); err != nil {
+		return err
+	}
+	if len(result.IDs) == 0 {
+		return unknownTransfer(c.Name(), id)
+	}
+	return nil
+}
+
+// get calls the API and decodes the JSON response into out. SABnzbd answers errors, such as a wrong API
+// key, with status 200 and an error message, they are returned as errors.
+func (c *SABnzbd) get(query url.Values, out any) error {
+	query.Set("output", "json")
+	query.Set("apikey", c.apiKey)
+	u := c.baseURL + "/api?" + query.Encode()
+
+	resp, err := c.httpClient.Get(u)
+	if err != nil {
+		// The error holds the URL, keep the API key out of the logs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = strings.ReplaceAll(urlErr.URL, c.apiKey, "xxxxx")
+		}
+		return fmt.Errorf("error performing request: %w", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return fmt.Errorf("error reading response body: %w", err)
+	}
+	if err := checkResponse(resp, body); err != nil {
+		return err
+	}
+
+	var result sabnzbdResult
+	if json.Unmarshal(body, &result) == nil && result.Status != nil && !*result.Status {
+		if strings.Contains(strings.ToLower(result.Error), "api key") {
+			return fmt.Errorf("SABnzbd %s: %w", result.Error, api.ErrUnauthorized)
+		}
+		return fmt.Errorf("SABnzbd %s command failed: %s", query.Get("mode"), result.Error)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error unmarshalling SABnzbd response: %w", err)
+	}
+	return nil
+}
+
+// parseFloat parses the numbers SABnzbd sends as strings, zero when empty or invalid
+func parseFloat(value string) float64 {
+	number, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return number
+}
+
+// parseTimeLeft parses a SABnzbd time left, h:mm:ss or d:hh:mm:ss, zero when unknown
+func parseTimeLeft(value string) time.Duration {
+	units := []time.Duration{time.Second, time.Minute, time.Hour, 24 * time.Hour}
+	parts := strings.Split(value, ":")
+	if len(parts) > len(units) {
+		return 0
+	}
+
+	var total time.Duration
+	for index := range parts {
+		number, err := strconv.Atoi(parts[len(parts)-1-index])
+		if err != nil {
+			return 0
+		}
+		total += time.Duration(number) * units[index]
+	}
+	return total
+}
@@ -0,0 +1,140 @@
+package downloader
+
+import (
+	"errors"
+	"main/api"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSABnzbd starts a stand-in for the SABnzbd API answering every request with reply, and returns a
+// client for it and the queries it received
+func newTestSABnzbd(t *testing.T, reply string) (*SABnzbd, *[]string) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api" {
+			t.Errorf("request path = %s, want /api", r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(server.Close)
+	return NewSABnzbd(server.URL, "key", "20", server.Client()), &queries
+}
+
+func TestSABnzbdStatus(t *testing.T) {
+	client, _ := newTestSABnzbd(t, `{"queue": {
+		"paused": false, "kbpersec": "1024.0", "diskspace1": "10.0", "diskspace2": "2.5", "speedlimit": "20",
+		"slots": [{"nzo_id": "SABnzbd_nzo_abcdef123", "filename": "Job", "status": "Paused", "percentage": "42",
+			"mb": "100", "timeleft": "0:01:30"}]
+	}}`)
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.DownloadSpeed != 1024*1024 {
+		t.Errorf("DownloadSpeed = %d, want %d", status.DownloadSpeed, 1024*1024)
+	}
+	// The smaller of the two disks counts
+	if status.FreeSpace != int64(2.5*(1<<30)) {
+		t.Errorf("FreeSpace = %d, want %d", status.FreeSpace, int64(2.5*(1<<30)))
+	}
+	if !status.SlowMode {
+		t.Error("SlowMode = false, want true with a 20% limit")
+	}
+
+	if len(status.Transfers) != 1 {
+		t.Fatalf("Transfers = %+v, want one", status.Transfers)
+	}
+	transfer := status.Transfers[0]
+	if transfer.ShortID() != "abcdef12" || !transfer.Paused || transfer.Progress != 42 || transfer.ETA != 90*time.Second {
+		t.Errorf("Transfer = %+v (short ID %s), want the slot", transfer, transfer.ShortID())
+	}
+}
+
+func TestSABnzbdErrorReplies(t *testing.T) {
+	tests := []struct {
+		name         string
+		reply        string
+		unauthorized bool
+		message      string
+	}{
+		{"wrong API key", `{"status": false, "error": "API Key Incorrect"}`, true, "API Key Incorrect"},
+		{"missing API key", `{"status": false, "error": "API Key Required"}`, true, "API Key Required"},
+		{"command failed", `{"status": false, "error": "not implemented"}`, false, "not implemented"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, _ := newTestSABnzbd(t, test.reply)
+
+			err := client.SetSlowMode(true)
+			if err == nil {
+				t.Fatal("SetSlowMode() error = nil, want the error reply")
+			}
+			if errors.Is(err, api.ErrUnauthorized) != test.unauthorized {
+				t.Errorf("errors.Is(%v, ErrUnauthorized) = %v, want %v", err, !test.unauthorized, test.unauthorized)
+			}
+			if !strings.Contains(err.Error(), test.message) {
+				t.Errorf("error = %v, want it to contain %q", err, test.message)
+			}
+		})
+	}
+}
+
+func TestSABnzbdUnknownTransfer(t *testing.T) {
+	client, queries := newTestSABnzbd(t, `{"status": true, "nzo_ids": []}`)
+
+	for name, command := range map[string]func() error{
+		"pause":  func() error { return client.Pause("SABnzbd_nzo_missing") },
+		"resume": func() error { return client.Resume("SABnzbd_nzo_missing") },
+		"delete": func() error { return client.Delete("SABnzbd_nzo_missing", true) },
+	} {
+		if err := command(); !errors.Is(err, api.ErrNotFound) {
+			t.Errorf("%s error = %v, want ErrNotFound", name, err)
+		}
+	}
+
+	for _, query := range *queries {
+		if !strings.Contains(query, "value=SABnzbd_nzo_missing") || !strings.Contains(query, "apikey=key") {
+			t.Errorf("query = %s, want the job ID and the API key", query)
+		}
+	}
+}
+
+func TestSABnzbdJobCommand(t *testing.T) {
+	client, queries := newTestSABnzbd(t, `{"status": true, "nzo_ids": ["SABnzbd_nzo_abc"]}`)
+
+	if err := client.Delete("SABnzbd_nzo_abc", true); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if query := (*queries)[0]; !strings.Contains(query, "name=delete") || !strings.Contains(query, "del_files=1") {
+		t.Errorf("query = %s, want a delete with del_files", query)
+	}
+}
+
+func TestParseTimeLeft(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"0:12:34", 12*time.Minute + 34*time.Second},
+		{"10:00:00", 10 * time.Hour},
+		{"1:02:12:34", 26*time.Hour + 12*time.Minute + 34*time.Second},
+		{"0:00:00", 0},
+		{"", 0},
+		{"unknown", 0},
+		{"1:xx:00", 0},
+		{"1:2:3:4:5", 0},
+	}
+
+	for _, test := range tests {
+		if got := parseTimeLeft(test.value); got != test.want {
+			t.Errorf("parseTimeLeft(%q) = %v, want %v", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package format
+
+import (
+	"fmt"
+)
+
+// Bytes returns a size in bytes in human readable form, e.g. "1.5 GB"
+func Bytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// Truncate shortens text to at most limit characters, marking the cut with an ellipsis
+func Truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
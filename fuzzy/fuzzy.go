@@ -0,0 +1,196 @@
+// Package fuzzy ranks text against a typed query, tolerating typos, partial words, word order and accents
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MinScore is the score below which a candidate is not considered a match
+const MinScore = 0.5
+
+// foldTable maps accented letters to their plain form, e.g. "é" to "e" and "æ" to "ae"
+var foldTable = map[rune]string{}
+
+func init() {
+	for plain, accented := range map[string]string{
+		"a": "àáâãäåāăą", "c": "çćĉċč", "d": "ďđð", "e": "èéêëēĕėęě", "g": "ĝğġģ", "h": "ĥħ",
+		"i": "ìíîïĩīĭįı", "j": "ĵ", "k": "ķ", "l": "ĺļľŀł", "n": "ñńņňŉ", "o": "òóôõöøōŏő",
+		"r": "ŕŗř", "s": "śŝşšș", "t": "ţťŧț", "u": "ùúûüũūŭůűų", "w": "ŵ", "y": "ýÿŷ", "z": "źżž",
+		"ae": "æ", "oe": "œ", "ss": "ß", "th": "þ",
+	} {
+		for _, r := range accented {
+			foldTable[r] = plain
+		}
+	}
+}
+
+// Normalize lowercases text, folds accented letters and strips punctuation so that e.g.
+// "Marvel's Agents of S.H.I.E.L.D." and "marvels agents of shield" compare equal. "&" is spelled out as "and".
+func Normalize(text string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(strings.ReplaceAll(text, "&", " and ")) {
+		if plain, ok := foldTable[r]; ok {
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteString(plain)
+			continue
+		}
+
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case r == '\'' || r == '’' || r == '.':
+			// dropped without a space so "marvel's" becomes "marvels" and "s.h.i.e.l.d." becomes "shield"
+		default:
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// Score rates how well candidate matches query from 0 to 1, 1 being identical once normalised.
+// It combines how many query words are found in the candidate (exactly, as a prefix or with a typo),
+// the trigram similarity of the whole texts, and a boost when the candidate starts with or contains the query.
+func Score(query, candidate string) float64 {
+	return score(Normalize(query), Normalize(candidate))
+}
+
+// score is Score on normalised texts
+func score(query, candidate string) float64 {
+	if query == "" || candidate == "" {
+		return 0
+	}
+	if query == candidate {
+		return 1
+	}
+
+	result := 0.6*tokenScore(query, candidate) + 0.4*trigramSimilarity(query, candidate)
+	if strings.HasPrefix(candidate, query) {
+		result += 0.15
+	} else if strings.Contains(candidate, query) {
+		result += 0.1
+	}
+
+	// Only an identical text scores 1
+	return min(result, 0.99)
+}
+
+// tokenScore averages, over the query words, how well each word matches its best candidate word:
+// 1 when equal, 0.9 when it is the start of the word, the edit similarity when close enough, 0 otherwise.
+func tokenScore(query, candidate string) float64 {
+	queryWords := strings.Fields(query)
+	candidateWords := strings.Fields(candidate)
+
+	total := 0.0
+	for _, queryWord := range queryWords {
+		best := 0.0
+		for _, candidateWord := range candidateWords {
+			switch {
+			case queryWord == candidateWord:
+				best = 1
+			case strings.HasPrefix(candidateWord, queryWord):
+				best = max(best, 0.9)
+			default:
+				length := max(len([]rune(queryWord)), len([]rune(candidateWord)))
+				similarity := 1 - float64(Levenshtein(queryWord, candidateWord))/float64(length)
+				if similarity >= 0.7 {
+					best = max(best, similarity)
+				}
+			}
+		}
+		total += best
+	}
+	return total / float64(len(queryWords))
+}
+
+// trigramSimilarity is the Jaccard similarity of the sets of three letter sequences of both texts
+func trigramSimilarity(a, b string) float64 {
+	trigramsA := trigrams(a)
+	trigramsB := trigrams(b)
+
+	shared := 0
+	for trigram := range trigramsA {
+		if trigramsB[trigram] {
+			shared++
+		}
+	}
+	union := len(trigramsA) + len(trigramsB) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// trigrams returns the three letter sequences of text, padded so short words have some
+func trigrams(text string) map[string]bool {
+	runes := []rune("  " + text + " ")
+	set := make(map[string]bool)
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+	return set
+}
+
+// Levenshtein returns the edit distance between two strings
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// Match is a ranked candidate, Index is its position in the list given to Rank
+type Match struct {
+	Index int
+	Score float64
+}
+
+// Rank scores the candidates against query and returns the best limit of them, best first.
+// Each candidate may be known by several keys, already normalised, its best key counts.
+// Candidates scoring below minScore are dropped, limit 0 keeps every match.
+func Rank(query string, keys [][]string, limit int, minScore float64) []Match {
+	normalized := Normalize(query)
+
+	var matches []Match
+	for index, candidateKeys := range keys {
+		best := 0.0
+		for _, key := range candidateKeys {
+			best = max(best, score(normalized, key))
+		}
+		if best >= minScore {
+			matches = append(matches, Match{Index: index, Score: best})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,149 @@
+package fuzzy
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Marvel's Agents of S.H.I.E.L.D.", "marvels agents of shield"},
+		{"Pokémon", "pokemon"},
+		{"La Casa de Papel: Atracón", "la casa de papel atracon"},
+		{"Law & Order", "law and order"},
+		{"Mr. Robot", "mr robot"},
+		{"The Office (US)", "the office us"},
+		{"Æon Flux", "aeon flux"},
+		{"  Doctor   Who -- 2005 ", "doctor who 2005"},
+		{"...", ""},
+	}
+
+	for _, test := range tests {
+		if got := Normalize(test.text); got != test.want {
+			t.Errorf("Normalize(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"breaking", "braeking", 2},
+		{"sevrance", "severance", 1},
+		{"pokémon", "pokemon", 1},
+	}
+
+	for _, test := range tests {
+		if got := Levenshtein(test.a, test.b); got != test.want {
+			t.Errorf("Levenshtein(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+// library is a list of series as they are indexed: the normalised title, the title with the year and the
+// alternate titles
+var library = []struct {
+	title string
+	keys  []string
+}{
+	{"Marvel's Agents of S.H.I.E.L.D.", []string{"marvels agents of shield", "marvels agents of shield 2013"}},
+	{"Pokémon", []string{"pokemon", "pokemon 1997", "pocket monsters"}},
+	{"Breaking Bad", []string{"breaking bad", "breaking bad 2008"}},
+	{"Severance", []string{"severance", "severance 2022"}},
+	{"Doctor Who (1963)", []string{"doctor who", "doctor who 1963"}},
+	{"Doctor Who (2005)", []string{"doctor who", "doctor who 2005"}},
+	{"Money Heist", []string{"money heist", "money heist 2017", "la casa de papel"}},
+	{"The Office (US)", []string{"the office us", "the office us 2005"}},
+	{"The Office (UK)", []string{"the office uk", "the office uk 2001"}},
+}
+
+// libraryKeys returns the keys of library for Rank
+func libraryKeys() [][]string {
+	keys := make([][]string, len(library))
+	for index, series := range library {
+		keys[index] = series.keys
+	}
+	return keys
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string // best match, empty for none
+	}{
+		{"exact", "Severance", "Severance"},
+		{"punctuation", "agents of shield", "Marvel's Agents of S.H.I.E.L.D."},
+		{"punctuation in the query", "S.H.I.E.L.D.", "Marvel's Agents of S.H.I.E.L.D."},
+		{"diacritics", "pokemon", "Pokémon"},
+		{"diacritics in the query", "Pokémon", "Pokémon"},
+		{"typo", "braking bad", "Breaking Bad"},
+		{"transposed letters", "sevreance", "Severance"},
+		{"partial word", "sever", "Severance"},
+		{"year", "doctor who 2005", "Doctor Who (2005)"},
+		{"other year", "doctor who 1963", "Doctor Who (1963)"},
+		{"title and year", "breaking bad 2008", "Breaking Bad"},
+		{"alternate title", "la casa de papel", "Money Heist"},
+		{"alternate title with accents", "La Casa de Pápel", "Money Heist"},
+		{"no match", "zzzzzz", ""},
+	}
+
+	keys := libraryKeys()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches := Rank(test.query, keys, 0, MinScore)
+			if test.want == "" {
+				if len(matches) != 0 {
+					t.Errorf("Rank(%q) = %v, want no match", test.query, matches)
+				}
+				return
+			}
+			if len(matches) == 0 {
+				t.Fatalf("Rank(%q) found nothing, want %q", test.query, test.want)
+			}
+			if got := library[matches[0].Index].title; got != test.want {
+				t.Errorf("Rank(%q) best match = %q, want %q", test.query, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRankLimit(t *testing.T) {
+	keys := libraryKeys()
+
+	matches := Rank("the office", keys, 1, MinScore)
+	if len(matches) != 1 {
+		t.Fatalf("Rank with limit 1 returned %d matches", len(matches))
+	}
+
+	all := Rank("the office", keys, 0, MinScore)
+	if len(all) != 2 {
+		t.Fatalf("Rank(%q) = %v, want both versions of The Office", "the office", all)
+	}
+	if all[0].Score < all[1].Score {
+		t.Errorf("Rank(%q) = %v, want the best match first", "the office", all)
+	}
+	if all[0] != matches[0] {
+		t.Errorf("Rank with limit 1 = %v, want the best match %v", matches[0], all[0])
+	}
+}
+
+// TestRankTie checks that titles only told apart by what the query leaves out score the same, so the bot
+// lists them instead of picking one
+func TestRankTie(t *testing.T) {
+	for _, query := range []string{"doctor who", "the office"} {
+		matches := Rank(query, libraryKeys(), 0, MinScore)
+		if len(matches) != 2 {
+			t.Fatalf("Rank(%q) = %v, want two matches", query, matches)
+		}
+		if matches[0].Score != matches[1].Score {
+			t.Errorf("Rank(%q) scores = %.2f and %.2f, want a tie", query, matches[0].Score, matches[1].Score)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Package index keeps a list fetched from a slow service in memory with the search keys of its items, so
+// searches and autocomplete never wait on the service once the list has been fetched
+package index
+
+import (
+	"log"
+	"main/fuzzy"
+	"sync"
+	"time"
+)
+
+// Match is an item found by Search with how well it matches the query, from 0 to 1
+type Match[T any] struct {
+	Item  T
+	Score float64
+}
+
+// Rank returns the best limit items matching the query by their search keys, best first, see fuzzy.Rank
+func Rank[T any](items []T, keys [][]string, query string, limit int) []Match[T] {
+	var matches []Match[T]
+	for _, match := range fuzzy.Rank(query, keys, limit, fuzzy.MinScore) {
+		matches = append(matches, Match[T]{Item: items[match.Index], Score: match.Score})
+	}
+	return matches
+}
+
+// fetchCall is a fetch in flight, the callers waiting for it share its result
+type fetchCall struct {
+	done chan struct{}
+	err  error
+}
+
+// Cache holds the list returned by fetch and the search keys of its items. The list is fetched again once it
+// is older than the refresh interval or after Invalidate. Only the first call waits for the fetch: afterwards
+// the previous list is served while a single fetch runs in the background, so a slow or failing service
+// never holds up the callers.
+type Cache[T any] struct {
+	name     string // what the list holds, for the logs, e.g. "Sonarr series"
+	fetch    func() ([]T, error)
+	keys     func(T) []string
+	interval time.Duration
+
+	mu         sync.Mutex
+	items      []T
+	itemKeys   [][]string // search keys of items, by index
+	loaded     bool       // items holds a fetched list, maybe a stale one
+	fetched    time.Time  // zero when the list is stale
+	generation int        // bumped by Invalidate so a fetch started before it does not count as fresh
+	call       *fetchCall // the fetch in flight, nil when there is none
+}
+
+// New creates an empty cache of the list returned by fetch, refreshed every interval
+func New[T any](name string, fetch func() ([]T, error), keys func(T) []string, interval time.Duration) *Cache[T] {
+	return &Cache[T]{name: name, fetch: fetch, keys: keys, interval: interval}
+}
+
+// All returns the cached list, waiting for it only when it has never been fetched
+func (c *Cache[T]) All() ([]T, error) {
+	items, _, err := c.snapshot()
+	return items, err
+}
+
+// Search ranks the cached items against query, waiting for the list only when it has never been fetched
+func (c *Cache[T]) Search(query string, limit int) ([]Match[T], error) {
+	items, keys, err := c.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return Rank(items, keys, query, limit), nil
+}
+
+// Refresh fetches the list now, whatever its age, and waits for the result
+func (c *Cache[T]) Refresh() error {
+	c.mu.Lock()
+	call := c.start()
+	c.mu.Unlock()
+
+	<-call.done
+	return call.err
+}
+
+// Invalidate marks the list stale and fetches it again in the background, calls keep getting the previous
+// list until the new one arrives
+func (c *Cache[T]) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.fetched = time.Time{}
+	c.generation++
+	if c.loaded {
+		c.start()
+	}
+}
+
+// Run refreshes the list every interval, it never returns
+func (c *Cache[T]) Run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Refresh(); err != nil {
+			log.Printf("Error refreshing the %s cache: %v", c.name, err)
+		}
+		<-ticker.C
+	}
+}
+
+// snapshot returns the current list and keys. A stale list is returned as it is while a fetch runs in the
+// background, only an empty cache waits for the fetch.
+func (c *Cache[T]) snapshot() ([]T, [][]string, error) {
+	c.mu.Lock()
+	if c.loaded {
+		if c.fetched.IsZero() || time.Since(c.fetched) >= c.interval {
+			c.start()
+		}
+		items, keys := c.items, c.itemKeys
+		c.mu.Unlock()
+		return items, keys, nil
+	}
+	call := c.start()
+	c.mu.Unlock()
+
+	<-call.done
+	if call.err != nil {
+		return nil, nil, call.err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.items, c.itemKeys, nil
+}
+
+// start returns the fetch in flight, starting one when there is none. The caller holds c.mu.
+func (c *Cache[T]) start() *fetchCall {
+	if c.call == nil {
+		c.call = &fetchCall{done: make(chan struct{})}
+		go c.run(c.call, c.generation)
+	}
+	return c.call
+}
+
+// run fetches the list without holding c.mu and swaps it in, then wakes up the callers waiting for it
+func (c *Cache[T]) run(call *fetchCall, generation int) {
+	items, err := c.fetch()
+
+	var keys [][]string
+	if err == nil {
+		keys = make([][]string, len(items))
+		for index := range items {
+			keys[index] = c.keys(items[index])
+		}
+	}
+
+	c.mu.Lock()
+	if err == nil {
+		c.items, c.itemKeys, c.loaded = items, keys, true
+		if generation == c.generation {
+			c.fetched = time.Now()
+		}
+	}
+	c.call = nil
+	c.mu.Unlock()
+
+	call.err = err
+	close(call.done)
+}
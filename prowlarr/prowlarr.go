@@ -0,0 +1,98 @@
+package prowlarr
+
+import (
+	"fmt"
+	"main/api"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Typed errors returned by the client, check them with errors.Is
+var (
+	ErrUnauthorized = api.ErrUnauthorized
+	ErrNotFound     = api.ErrNotFound
+	ErrServer       = api.ErrServer
+)
+
+// Service is the part of the Prowlarr v1 API used by the bot.
+// It is implemented by Client, handlers depend on the interface so they can be run against a fake.
+type Service interface {
+	// Indexers returns the configured indexers
+	Indexers() ([]Indexer, error)
+	// IndexerStatuses returns the indexers that failed recently and until when they are disabled
+	IndexerStatuses() ([]IndexerStatus, error)
+	// IndexerStats returns the query counts and average response time of each indexer
+	IndexerStats() ([]IndexerStats, error)
+	// Search searches every enabled indexer, limited to the given categories when there are any
+	Search(query string, categories []int, limit int) ([]SearchResult, error)
+}
+
+// Config holds the settings needed to talk to a Prowlarr instance
+type Config struct {
+	BaseURL string // e.g. http://10.23.0.3:9696
+	APIKey  string
+	Timeout time.Duration
+	Retry   api.RetryPolicy
+}
+
+// searchTimeout is the timeout of Search, which waits for every indexer to answer
+const searchTimeout = 2 * time.Minute
+
+// Client talks to the Prowlarr v1 API
+type Client struct {
+	api    *api.Client
+	search *api.Client // used for searches, with a longer timeout and no retries
+}
+
+// NewClient creates a Prowlarr client from config
+func NewClient(config Config) *Client {
+	return &Client{
+		api:    api.NewClient(config.BaseURL+"/api/v1", config.APIKey, config.Timeout, config.Retry),
+		search: api.NewClient(config.BaseURL+"/api/v1", config.APIKey, max(config.Timeout, searchTimeout), api.RetryPolicy{}),
+	}
+}
+
+// BaseURL constructs the base URL of a Prowlarr instance
+func BaseURL(prowlarrInstance, prowlarrPort string) string {
+	return fmt.Sprintf("http://%s:%s", prowlarrInstance, prowlarrPort)
+}
+
+// Indexers returns the configured indexers
+func (c *Client) Indexers() ([]Indexer, error) {
+	var indexers []Indexer
+	err := c.api.Get("/indexer", nil, &indexers)
+	return indexers, err
+}
+
+// IndexerStatuses returns the indexers that failed recently and until when they are disabled
+func (c *Client) IndexerStatuses() ([]IndexerStatus, error) {
+	var statuses []IndexerStatus
+	err := c.api.Get("/indexerstatus", nil, &statuses)
+	return statuses, err
+}
+
+// IndexerStats returns the query counts and average response time of each indexer
+func (c *Client) IndexerStats() ([]IndexerStats, error) {
+	var stats struct {
+		Indexers []IndexerStats `json:"indexers"`
+	}
+	err := c.api.Get("/indexerstats", nil, &stats)
+	return stats.Indexers, err
+}
+
+// Search searches every enabled indexer, limited to the given categories when there are any
+func (c *Client) Search(query string, categories []int, limit int) ([]SearchResult, error) {
+	values := url.Values{
+		"query": {query},
+		"type":  {"search"},
+		"limit": {strconv.Itoa(limit)},
+	}
+	for _, category := range categories {
+		values.Add("categories", strconv.Itoa(category))
+	}
+
+	var results []SearchResult
+	err := c.search.Get("/search", values, &results)
+	return results, err
+}
@@ -0,0 +1,60 @@
+package prowlarr
+
+import "time"
+
+// Indexer is an indexer configured in Prowlarr
+type Indexer struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Enable   bool   `json:"enable"`
+	Protocol string `json:"protocol"` // torrent or usenet
+	Privacy  string `json:"privacy"`  // public, semiPrivate or private
+	Priority int    `json:"priority"`
+}
+
+// IndexerStatus is the failure state of an indexer, Prowlarr only reports indexers that failed recently
+type IndexerStatus struct {
+	ID                int        `json:"id"`
+	IndexerID         int        `json:"indexerId"`
+	DisabledTill      *time.Time `json:"disabledTill"` // set while Prowlarr backs off after failures
+	MostRecentFailure *time.Time `json:"mostRecentFailure"`
+	InitialFailure    *time.Time `json:"initialFailure"`
+}
+
+// IndexerStats are the query counts and response time of an indexer
+type IndexerStats struct {
+	IndexerID             int    `json:"indexerId"`
+	IndexerName           string `json:"indexerName"`
+	AverageResponseTime   int    `json:"averageResponseTime"` // milliseconds
+	NumberOfQueries       int    `json:"numberOfQueries"`
+	NumberOfGrabs         int    `json:"numberOfGrabs"`
+	NumberOfFailedQueries int    `json:"numberOfFailedQueries"`
+}
+
+// Category is a Newznab category, e.g. 5000 TV or 2000 Movies
+type Category struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Newznab categories searched by !search --cat
+const (
+	CategoryMovies = 2000
+	CategoryTV     = 5000
+)
+
+// SearchResult is a release found by a search across the indexers
+type SearchResult struct {
+	GUID        string     `json:"guid"`
+	Title       string     `json:"title"`
+	Indexer     string     `json:"indexer"`
+	IndexerID   int        `json:"indexerId"`
+	Size        int64      `json:"size"`
+	Seeders     *int       `json:"seeders"` // nil for usenet
+	Leechers    *int       `json:"leechers"`
+	Grabs       *int       `json:"grabs"`
+	PublishDate time.Time  `json:"publishDate"`
+	Protocol    string     `json:"protocol"`
+	Categories  []Category `json:"categories"`
+	InfoURL     string     `json:"infoUrl"`
+}
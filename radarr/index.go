@@ -0,0 +1,100 @@
+package radarr
+
+import (
+	"main/fuzzy"
+	"main/index"
+	"strconv"
+	"time"
+)
+
+// SearchKeys returns the normalised titles (see fuzzy.Normalize) a movie is found by: its title, its
+// original and alternate titles and its title followed by its year.
+func SearchKeys(movie Movie) []string {
+	title := fuzzy.Normalize(movie.Title)
+	keys := []string{title}
+	if movie.Year != 0 {
+		keys = append(keys, title+" "+strconv.Itoa(movie.Year))
+	}
+	alternates := []string{movie.OriginalTitle}
+	for _, alternate := range movie.AlternateTitles {
+		alternates = append(alternates, alternate.Title)
+	}
+	for _, alternate := range alternates {
+		if key := fuzzy.Normalize(alternate); key != "" && key != title {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// MovieMatch is a movie found by SearchMovies with how well it matches the query, from 0 to 1
+type MovieMatch struct {
+	Movie Movie
+	Score float64
+}
+
+// RankMovies returns the best limit movies matching the query, best first, see fuzzy.Rank
+func RankMovies(movies []Movie, query string, limit int) []MovieMatch {
+	keys := make([][]string, len(movies))
+	for i, movie := range movies {
+		keys[i] = SearchKeys(movie)
+	}
+	return movieMatches(index.Rank(movies, keys, query, limit))
+}
+
+// movieMatches converts the matches of the index package
+func movieMatches(matches []index.Match[Movie]) []MovieMatch {
+	var converted []MovieMatch
+	for _, match := range matches {
+		converted = append(converted, MovieMatch{Movie: match.Item, Score: match.Score})
+	}
+	return converted
+}
+
+// CachedClient is a Service keeping the list of movies and their search keys in memory, see index.Cache.
+// The list is fetched again once it is older than the refresh interval, after Invalidate, or after a movie
+// is added through the client. Every other call goes straight to the wrapped Service.
+type CachedClient struct {
+	Service
+	cache *index.Cache[Movie]
+}
+
+// NewCachedClient wraps client with a movie cache refreshed every interval
+func NewCachedClient(client Service, interval time.Duration) *CachedClient {
+	return &CachedClient{
+		Service: client,
+		cache:   index.New("Radarr movie", client.AllMovies, SearchKeys, interval),
+	}
+}
+
+// AllMovies returns the cached movies, only waiting for Radarr before the first fetch
+func (c *CachedClient) AllMovies() ([]Movie, error) {
+	return c.cache.All()
+}
+
+// SearchMovies ranks the cached movies against query, only waiting for Radarr before the first fetch
+func (c *CachedClient) SearchMovies(query string, limit int) ([]MovieMatch, error) {
+	matches, err := c.cache.Search(query, limit)
+	return movieMatches(matches), err
+}
+
+// Refresh fetches the movies from Radarr now, whatever the age of the cache
+func (c *CachedClient) Refresh() error {
+	return c.cache.Refresh()
+}
+
+// Invalidate fetches the movies again in the background, calls get the previous list until then
+func (c *CachedClient) Invalidate() {
+	c.cache.Invalidate()
+}
+
+// Run refreshes the cache every interval so searches rarely wait for Radarr, it never returns
+func (c *CachedClient) Run() {
+	c.cache.Run()
+}
+
+// AddMovie adds a movie and invalidates the cache
+func (c *CachedClient) AddMovie(movie NewMovie) (Movie, error) {
+	defer c.Invalidate()
+	return c.Service.AddMovie(movie)
+}
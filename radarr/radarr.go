@@ -0,0 +1,142 @@
+package radarr
+
+import (
+	"fmt"
+	"main/api"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Typed errors returned by the client, check them with errors.Is
+var (
+	ErrUnauthorized = api.ErrUnauthorized
+	ErrNotFound     = api.ErrNotFound
+	ErrServer       = api.ErrServer
+)
+
+// Service is the part of the Radarr v3 API used by the bot.
+// It is implemented by Client, handlers depend on the interface so they can be run against a fake.
+type Service interface {
+	// Lookup searches for new movies by name (TMDb lookup through Radarr)
+	Lookup(term string) ([]Movie, error)
+	// AllMovies returns every movie added to the Radarr instance
+	AllMovies() ([]Movie, error)
+	// SearchMovies returns the best limit movies added to Radarr matching the query by title, alternate
+	// title or title and year, best first
+	SearchMovies(query string, limit int) ([]MovieMatch, error)
+	// AddMovie adds a movie found by Lookup to Radarr
+	AddMovie(movie NewMovie) (Movie, error)
+	// QualityProfiles returns the quality profiles a movie can be assigned
+	QualityProfiles() ([]QualityProfile, error)
+	// RootFolders returns the folders movies can be stored in
+	RootFolders() ([]RootFolder, error)
+	// Calendar returns the monitored movies with a cinema, digital or physical release between start and end
+	Calendar(start, end time.Time) ([]Movie, error)
+	// Queue returns a page of the download queue with the movie of each item
+	Queue(page, pageSize int) (QueuePage, error)
+	// RemoveFromQueue removes an item from the queue and the download client, optionally blocklisting the release
+	RemoveFromQueue(id int, blocklist bool) error
+}
+
+// Config holds the settings needed to talk to a Radarr instance
+type Config struct {
+	BaseURL string // e.g. http://10.23.0.3:7878
+	APIKey  string
+	Timeout time.Duration
+	Retry   api.RetryPolicy
+}
+
+// Client talks to the Radarr v3 API
+type Client struct {
+	api *api.Client
+}
+
+// NewClient creates a Radarr client from config
+func NewClient(config Config) *Client {
+	return &Client{api: api.NewClient(config.BaseURL+"/api/v3", config.APIKey, config.Timeout, config.Retry)}
+}
+
+// BaseURL constructs the base URL of a Radarr instance
+func BaseURL(radarrInstance, radarrPort string) string {
+	return fmt.Sprintf("http://%s:%s", radarrInstance, radarrPort)
+}
+
+// Lookup searches for new movies by name
+func (c *Client) Lookup(term string) ([]Movie, error) {
+	var movies []Movie
+	err := c.api.Get("/movie/lookup", url.Values{"term": {term}}, &movies)
+	return movies, err
+}
+
+// AllMovies returns every movie added to the Radarr instance
+func (c *Client) AllMovies() ([]Movie, error) {
+	var movies []Movie
+	err := c.api.Get("/movie", nil, &movies)
+	return movies, err
+}
+
+// SearchMovies returns the best limit movies added to Radarr matching the query, best first
+func (c *Client) SearchMovies(query string, limit int) ([]MovieMatch, error) {
+	movies, err := c.AllMovies()
+	if err != nil {
+		return nil, err
+	}
+	return RankMovies(movies, query, limit), nil
+}
+
+// AddMovie adds a movie found by Lookup to Radarr
+func (c *Client) AddMovie(movie NewMovie) (Movie, error) {
+	var added Movie
+	err := c.api.Post("/movie", nil, movie, &added)
+	return added, err
+}
+
+// QualityProfiles returns the quality profiles a movie can be assigned
+func (c *Client) QualityProfiles() ([]QualityProfile, error) {
+	var profiles []QualityProfile
+	err := c.api.Get("/qualityprofile", nil, &profiles)
+	return profiles, err
+}
+
+// RootFolders returns the folders movies can be stored in
+func (c *Client) RootFolders() ([]RootFolder, error) {
+	var folders []RootFolder
+	err := c.api.Get("/rootfolder", nil, &folders)
+	return folders, err
+}
+
+// Calendar returns the monitored movies with a cinema, digital or physical release between start and end
+func (c *Client) Calendar(start, end time.Time) ([]Movie, error) {
+	query := url.Values{
+		"start":       {start.UTC().Format(time.RFC3339)},
+		"end":         {end.UTC().Format(time.RFC3339)},
+		"unmonitored": {"false"},
+	}
+
+	var movies []Movie
+	err := c.api.Get("/calendar", query, &movies)
+	return movies, err
+}
+
+// Queue returns a page of the download queue with the movie of each item
+func (c *Client) Queue(page, pageSize int) (QueuePage, error) {
+	query := url.Values{
+		"page":         {strconv.Itoa(page)},
+		"pageSize":     {strconv.Itoa(pageSize)},
+		"includeMovie": {"true"},
+	}
+
+	var queue QueuePage
+	err := c.api.Get("/queue", query, &queue)
+	return queue, err
+}
+
+// RemoveFromQueue removes an item from the queue and the download client, optionally blocklisting the release
+func (c *Client) RemoveFromQueue(id int, blocklist bool) error {
+	query := url.Values{
+		"removeFromClient": {"true"},
+		"blocklist":        {strconv.FormatBool(blocklist)},
+	}
+	return c.api.Delete(fmt.Sprintf("/queue/%d", id), query)
+}
@@ -0,0 +1,135 @@
+package radarr
+
+import "time"
+
+// Movie represents a movie as returned by the Radarr movie and lookup endpoints
+type Movie struct {
+	ID                  int              `json:"id"` // zero for lookup results not yet added to Radarr
+	Title               string           `json:"title"`
+	OriginalTitle       string           `json:"originalTitle"`
+	AlternateTitles     []AlternateTitle `json:"alternateTitles"`
+	TitleSlug           string           `json:"titleSlug"`
+	TmdbID              int              `json:"tmdbId"`
+	ImdbID              string           `json:"imdbId"`
+	Year                int              `json:"year"`
+	Studio              string           `json:"studio"`
+	Overview            string           `json:"overview"`
+	Status              string           `json:"status"` // tba, announced, inCinemas, released or deleted
+	Genres              []string         `json:"genres"`
+	Runtime             int              `json:"runtime"`
+	Monitored           bool             `json:"monitored"`
+	HasFile             bool             `json:"hasFile"`
+	IsAvailable         bool             `json:"isAvailable"`
+	MinimumAvailability string           `json:"minimumAvailability"`
+	InCinemas           *time.Time       `json:"inCinemas"`
+	PhysicalRelease     *time.Time       `json:"physicalRelease"`
+	DigitalRelease      *time.Time       `json:"digitalRelease"`
+	QualityProfileID    int              `json:"qualityProfileId"`
+	Path                string           `json:"path"`
+	RootFolderPath      string           `json:"rootFolderPath"`
+	SizeOnDisk          int64            `json:"sizeOnDisk"`
+	Images              []Image          `json:"images"`
+}
+
+// Poster returns the URL of the movie poster, empty when there is none
+func (m Movie) Poster() string {
+	for _, image := range m.Images {
+		if image.CoverType == "poster" {
+			if image.RemoteURL != "" {
+				return image.RemoteURL
+			}
+			return image.URL
+		}
+	}
+	return ""
+}
+
+// AlternateTitle is another title a movie is known by, e.g. its original or a regional title
+type AlternateTitle struct {
+	Title string `json:"title"`
+}
+
+// Image is a poster or fanart image of a movie
+type Image struct {
+	CoverType string `json:"coverType"`
+	URL       string `json:"url"`
+	RemoteURL string `json:"remoteUrl"`
+}
+
+// QualityProfile is a Radarr quality profile
+type QualityProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// RootFolder is a folder Radarr stores movies in
+type RootFolder struct {
+	ID         int    `json:"id"`
+	Path       string `json:"path"`
+	Accessible bool   `json:"accessible"`
+	FreeSpace  int64  `json:"freeSpace"`
+}
+
+// MinimumAvailabilities are the values accepted by NewMovie.MinimumAvailability, in the order shown to users.
+// Radarr only grabs a release once the movie has reached this stage.
+var MinimumAvailabilities = []string{"announced", "inCinemas", "released"}
+
+// AddOptions controls whether the movie is monitored and searched for when it is added
+type AddOptions struct {
+	Monitor        string `json:"monitor"` // movieOnly or none
+	SearchForMovie bool   `json:"searchForMovie"`
+}
+
+// NewMovie is the body of an add movie request, built from a lookup result
+type NewMovie struct {
+	Title               string     `json:"title"`
+	TitleSlug           string     `json:"titleSlug"`
+	TmdbID              int        `json:"tmdbId"`
+	Year                int        `json:"year"`
+	Images              []Image    `json:"images"`
+	QualityProfileID    int        `json:"qualityProfileId"`
+	RootFolderPath      string     `json:"rootFolderPath"`
+	Monitored           bool       `json:"monitored"`
+	MinimumAvailability string     `json:"minimumAvailability"`
+	AddOptions          AddOptions `json:"addOptions"`
+}
+
+// QueueItem is a download in progress or waiting for import
+type QueueItem struct {
+	ID                    int             `json:"id"`
+	MovieID               int             `json:"movieId"`
+	Title                 string          `json:"title"`
+	Size                  float64         `json:"size"`
+	SizeLeft              float64         `json:"sizeleft"`
+	TimeLeft              string          `json:"timeleft"` // e.g. 00:12:34, empty when unknown
+	Status                string          `json:"status"`
+	TrackedDownloadStatus string          `json:"trackedDownloadStatus"` // ok, warning or error
+	TrackedDownloadState  string          `json:"trackedDownloadState"`
+	StatusMessages        []StatusMessage `json:"statusMessages"`
+	ErrorMessage          string          `json:"errorMessage"`
+	DownloadClient        string          `json:"downloadClient"`
+	Protocol              string          `json:"protocol"`
+	Movie                 *Movie          `json:"movie"`
+}
+
+// Progress returns the downloaded percentage of the item
+func (q QueueItem) Progress() float64 {
+	if q.Size <= 0 {
+		return 0
+	}
+	return (q.Size - q.SizeLeft) / q.Size * 100
+}
+
+// StatusMessage is a warning or error Radarr reports for a queue item
+type StatusMessage struct {
+	Title    string   `json:"title"`
+	Messages []string `json:"messages"`
+}
+
+// QueuePage is a page of the download queue
+type QueuePage struct {
+	Page         int         `json:"page"`
+	PageSize     int         `json:"pageSize"`
+	TotalRecords int         `json:"totalRecords"`
+	Records      []QueueItem `json:"records"`
+}
@@ -0,0 +1,108 @@
+package sonarr
+
+import (
+	"main/fuzzy"
+	"main/index"
+	"strconv"
+	"time"
+)
+
+// SearchKeys returns the normalised titles (see fuzzy.Normalize) a series is found by: its title, its
+// alternate titles and its title followed by its year.
+func SearchKeys(series Series) []string {
+	title := fuzzy.Normalize(series.Title)
+	keys := []string{title}
+	if series.Year != 0 {
+		keys = append(keys, title+" "+strconv.Itoa(series.Year))
+	}
+	for _, alternate := range series.AlternateTitles {
+		if key := fuzzy.Normalize(alternate.Title); key != "" && key != title {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// SeriesMatch is a series found by SearchSeries with how well it matches the query, from 0 to 1
+type SeriesMatch struct {
+	Series Series
+	Score  float64
+}
+
+// RankSeries returns the best limit series matching the query, best first, see fuzzy.Rank
+func RankSeries(allSeries []Series, query string, limit int) []SeriesMatch {
+	keys := make([][]string, len(allSeries))
+	for i, series := range allSeries {
+		keys[i] = SearchKeys(series)
+	}
+	return seriesMatches(index.Rank(allSeries, keys, query, limit))
+}
+
+// seriesMatches converts the matches of the index package
+func seriesMatches(matches []index.Match[Series]) []SeriesMatch {
+	var converted []SeriesMatch
+	for _, match := range matches {
+		converted = append(converted, SeriesMatch{Series: match.Item, Score: match.Score})
+	}
+	return converted
+}
+
+// CachedClient is a Service keeping the list of series and their search keys in memory, see index.Cache.
+// The list is fetched again once it is older than the refresh interval, after Invalidate, or after a series
+// is added, updated or deleted through the client. Every other call goes straight to the wrapped Service.
+type CachedClient struct {
+	Service
+	cache *index.Cache[Series]
+}
+
+// NewCachedClient wraps client with a series cache refreshed every interval
+func NewCachedClient(client Service, interval time.Duration) *CachedClient {
+	return &CachedClient{
+		Service: client,
+		cache:   index.New("Sonarr series", client.AllSeries, SearchKeys, interval),
+	}
+}
+
+// AllSeries returns the cached series, only waiting for Sonarr before the first fetch
+func (c *CachedClient) AllSeries() ([]Series, error) {
+	return c.cache.All()
+}
+
+// SearchSeries ranks the cached series against query, only waiting for Sonarr before the first fetch
+func (c *CachedClient) SearchSeries(query string, limit int) ([]SeriesMatch, error) {
+	matches, err := c.cache.Search(query, limit)
+	return seriesMatches(matches), err
+}
+
+// Refresh fetches the series from Sonarr now, whatever the age of the cache
+func (c *CachedClient) Refresh() error {
+	return c.cache.Refresh()
+}
+
+// Invalidate fetches the series again in the background, calls get the previous list until then
+func (c *CachedClient) Invalidate() {
+	c.cache.Invalidate()
+}
+
+// Run refreshes the cache every interval so searches rarely wait for Sonarr, it never returns
+func (c *CachedClient) Run() {
+	c.cache.Run()
+}
+
+// AddSeries adds a series and invalidates the cache
+func (c *CachedClient) AddSeries(series NewSeries) (Series, error) {
+	defer c.Invalidate()
+	return c.Service.AddSeries(series)
+}
+
+// UpdateSeries updates a series and invalidates the cache
+func (c *CachedClient) UpdateSeries(id int, update SeriesUpdate) (Series, error) {
+	defer c.Invalidate()
+	return c.Service.UpdateSeries(id, update)
+}
+
+// DeleteSeries deletes a series and invalidates the cache
+func (c *CachedClient) DeleteSeries(id int, deleteFiles, addImportListExclusion bool) error {
+	defer c.Invalidate()
+	return c.Service.DeleteSeries(id, deleteFiles, addImportListExclusion)
+}
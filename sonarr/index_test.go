@@ -0,0 +1,122 @@
+package sonarr
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingService is a Service whose AllSeries waits until a value is sent on release, every other method
+// panics on the nil Service
+type blockingService struct {
+	Service
+	release chan []Series
+	mu      sync.Mutex
+	calls   int
+}
+
+// AllSeries counts the call and returns the series sent on release
+func (f *blockingService) AllSeries() ([]Series, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+
+	series, ok := <-f.release
+	if !ok {
+		return nil, errors.New("Sonarr is down")
+	}
+	return series, nil
+}
+
+// fetches returns how many times AllSeries was called
+func (f *blockingService) fetches() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+// within fails the test when call does not return within a second
+func within(t *testing.T, name string, call func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		call()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("%s blocked while the cache was refreshing", name)
+	}
+}
+
+func TestCachedClientServesStaleSeriesWhileRefreshing(t *testing.T) {
+	fake := &blockingService{release: make(chan []Series)}
+	client := NewCachedClient(fake, time.Hour)
+
+	// The first search has nothing to serve and waits for Sonarr
+	go func() { fake.release <- []Series{{ID: 1, Title: "Severance"}} }()
+	matches, err := client.SearchSeries("severance", 0)
+	if err != nil || len(matches) != 1 {
+		t.Fatalf("SearchSeries() = %v, %v, want Severance", matches, err)
+	}
+
+	// A series was added: the cache refetches in the background, blocked until release
+	client.Invalidate()
+	within(t, "SearchSeries", func() {
+		matches, err = client.SearchSeries("severance", 0)
+	})
+	if err != nil || len(matches) != 1 || matches[0].Series.ID != 1 {
+		t.Errorf("SearchSeries() during the refresh = %v, %v, want the previous list", matches, err)
+	}
+	within(t, "AllSeries", func() {
+		client.AllSeries()
+	})
+	if fake.fetches() > 2 {
+		t.Errorf("%d fetches, want a single refresh in flight", fake.fetches())
+	}
+
+	// Once the refresh is done the new series is found
+	fake.release <- []Series{{ID: 1, Title: "Severance"}, {ID: 2, Title: "Slow Horses"}}
+	for attempt := 0; ; attempt++ {
+		matches, err = client.SearchSeries("slow horses", 0)
+		if err == nil && len(matches) == 1 {
+			break
+		}
+		if attempt == 100 {
+			t.Fatalf("SearchSeries() after the refresh = %v, %v, want Slow Horses", matches, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if fake.fetches() != 2 {
+		t.Errorf("%d fetches, want 2", fake.fetches())
+	}
+}
+
+func TestCachedClientFetchErrors(t *testing.T) {
+	fake := &blockingService{release: make(chan []Series)}
+	client := NewCachedClient(fake, time.Hour)
+
+	// Without a list to fall back on the error is returned
+	go func() { close(fake.release) }()
+	if _, err := client.AllSeries(); err == nil {
+		t.Fatal("AllSeries() error = nil, want the fetch error")
+	}
+
+	fake.release = make(chan []Series, 1)
+	fake.release <- []Series{{ID: 1, Title: "Severance"}}
+	if err := client.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	// A failed refresh keeps the previous list
+	close(fake.release)
+	if err := client.Refresh(); err == nil {
+		t.Error("Refresh() error = nil, want the fetch error")
+	}
+	series, err := client.AllSeries()
+	if err != nil || len(series) != 1 {
+		t.Errorf("AllSeries() after a failed refresh = %v, %v, want the previous list", series, err)
+	}
+}
@@ -0,0 +1,421 @@
+package sonarr
+
+import (
+	"fmt"
+	"main/api"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Typed errors returned by the client, check them with errors.Is
+var (
+	ErrUnauthorized = api.ErrUnauthorized
+	ErrNotFound     = api.ErrNotFound
+	ErrServer       = api.ErrServer
+)
+
+// Service is the part of the Sonarr v3 API used by the bot.
+// It is implemented by Client, handlers depend on the interface so they can be run against a fake.
+type Service interface {
+	// Lookup searches for new series by name (TheTVDB lookup through Sonarr)
+	Lookup(term string) ([]Series, error)
+	// AllSeries returns every series added to the Sonarr instance
+	AllSeries() ([]Series, error)
+	// SearchSeries returns the best limit series added to Sonarr matching the query by title, alternate
+	// title or title and year, best first
+	SearchSeries(query string, limit int) ([]SeriesMatch, error)
+	// Series returns a single series by ID
+	Series(id int) (Series, error)
+	// AddSeries adds a series found by Lookup to Sonarr
+	AddSeries(series NewSeries) (Series, error)
+	// UpdateSeries applies changes to a series, such as its monitored state, and returns the result
+	UpdateSeries(id int, update SeriesUpdate) (Series, error)
+	// DeleteSeries removes a series from Sonarr, optionally deleting its files and excluding it from import lists
+	DeleteSeries(id int, deleteFiles, addImportListExclusion bool) error
+	// QualityProfiles returns the quality profiles a series can be assigned
+	QualityProfiles() ([]QualityProfile, error)
+	// LanguageProfiles returns the language profiles, ErrNotFound on Sonarr v4 which has none
+	LanguageProfiles() ([]LanguageProfile, error)
+	// RootFolders returns the folders series can be stored in
+	RootFolders() ([]RootFolder, error)
+	// Tags returns the tags that can be put on series
+	Tags() ([]Tag, error)
+	// CreateTag adds a tag, Sonarr stores the label in lowercase
+	CreateTag(label string) (Tag, error)
+	// Calendar returns the monitored episodes airing between start and end, with their series
+	Calendar(start, end time.Time) ([]Episode, error)
+	// Queue returns a page of the download queue with the series and episode of each item
+	Queue(page, pageSize int) (QueuePage, error)
+	// RemoveFromQueue removes an item from the queue and the download client, optionally blocklisting the release
+	RemoveFromQueue(id int, blocklist bool) error
+	// MissingEpisodes returns a page of the monitored episodes that have aired but have no file, newest first
+	MissingEpisodes(page, pageSize int) (EpisodePage, error)
+	// Episodes returns every episode of a series
+	Episodes(seriesID int) ([]Episode, error)
+	// History returns a page of the history, newest first
+	History(page, pageSize int) (HistoryPage, error)
+	// HistorySince returns the history recorded after the given time
+	HistorySince(since time.Time) ([]HistoryRecord, error)
+	// SeriesHistory returns the history of a series
+	SeriesHistory(seriesID int) ([]HistoryRecord, error)
+	// SystemStatus returns the version and start time of Sonarr
+	SystemStatus() (SystemStatus, error)
+	// Health returns the issues currently reported by the Sonarr health checks
+	Health() ([]HealthCheck, error)
+	// DiskSpace returns the free and total space of the disks Sonarr can see
+	DiskSpace() ([]DiskSpace, error)
+	// Releases searches the indexers for the releases of an episode, in Sonarr's order of preference
+	Releases(episodeID int) ([]Release, error)
+	// GrabRelease sends a release found by Releases to the download client
+	GrabRelease(guid string, indexerID int) error
+	// RunCommand queues a command such as EpisodeSearch
+	RunCommand(command NewCommand) (Command, error)
+	// CommandStatus returns the current state of a queued command
+	CommandStatus(id int) (Command, error)
+}
+
+// Config holds the settings needed to talk to a Sonarr instance
+type Config struct {
+	BaseURL string // e.g. http://10.23.0.3:8989
+	APIKey  string
+	Timeout time.Duration
+	Retry   api.RetryPolicy
+}
+
+// releaseSearchTimeout is the timeout of Releases, which waits for every indexer to answer
+const releaseSearchTimeout = 2 * time.Minute
+
+// Client talks to the Sonarr v3 API
+type Client struct {
+	api    *api.Client
+	search *api.Client // used for release searches, with a longer timeout and no retries
+}
+
+// NewClient creates a Sonarr client from config
+func NewClient(config Config) *Client {
+	return &Client{
+		api:    api.NewClient(config.BaseURL+"/api/v3", config.APIKey, config.Timeout, config.Retry),
+		search: api.NewClient(config.BaseURL+"/api/v3", config.APIKey, max(config.Timeout, releaseSearchTimeout), api.RetryPolicy{}),
+	}
+}
+
+// BaseURL constructs the base URL of a Sonarr instance
+func BaseURL(sonarrInstance, sonarrPort string) string {
+	return fmt.Sprintf("http://%s:%s", sonarrInstance, sonarrPort)
+}
+
+// Lookup searches for new series by name
+func (c *Client) Lookup(term string) ([]Series, error) {
+	var series []Series
+	err := c.api.Get("/series/lookup", url.Values{"term": {term}}, &series)
+	return series, err
+}
+
+// AllSeries returns every series added to the Sonarr instance
+func (c *Client) AllSeries() ([]Series, error) {
+	var series []Series
+	err := c.api.Get("/series", nil, &series)
+	return series, err
+}
+
+// SearchSeries returns the best limit series added to Sonarr matching the query, best first
+func (c *Client) SearchSeries(query string, limit int) ([]SeriesMatch, error) {
+	series, err := c.AllSeries()
+	if err != nil {
+		return nil, err
+	}
+	return RankSeries(series, query, limit), nil
+}
+
+// Series returns a single series by ID
+func (c *Client) Series(id int) (Series, error) {
+	var series Series
+	err := c.api.Get(fmt.Sprintf("/series/%d", id), nil, &series)
+	return series, err
+}
+
+// AddSeries adds a series found by Lookup to Sonarr
+func (c *Client) AddSeries(series NewSeries) (Series, error) {
+	var added Series
+	err := c.api.Post("/series", nil, series, &added)
+	return added, err
+}
+
+// UpdateSeries applies changes to a series, such as its monitored state, and returns the result.
+// Sonarr replaces the whole series on update, so the full resource is fetched as raw JSON and sent back
+// with only the changed fields modified, keeping the fields Series does not model.
+func (c *Client) UpdateSeries(id int, update SeriesUpdate) (Series, error) {
+	path := fmt.Sprintf("/series/%d", id)
+
+	var raw map[string]any
+	if err := c.api.Get(path, nil, &raw); err != nil {
+		return Series{}, err
+	}
+
+	if update.Monitored != nil {
+		raw["monitored"] = *update.Monitored
+	}
+
+	if update.QualityProfileID != nil {
+		raw["qualityProfileId"] = *update.QualityProfileID
+	}
+
+	if len(update.AddTags) > 0 || len(update.RemoveTags) > 0 {
+		raw["tags"] = updateTags(raw["tags"], update.AddTags, update.RemoveTags)
+	}
+
+	// Moving to another root folder keeps the series folder name
+	var query url.Values
+	if update.RootFolderPath != "" {
+		oldPath, _ := raw["path"].(string)
+		raw["rootFolderPath"] = update.RootFolderPath
+		raw["path"] = joinPath(update.RootFolderPath, pathBase(oldPath))
+		query = url.Values{"moveFiles": {"true"}}
+	}
+
+	if len(update.SeasonMonitored) > 0 {
+		seasons, _ := raw["seasons"].([]any)
+		for _, entry := range seasons {
+			season, ok := entry.(map[string]any)
+			if !ok {
+				continue
+			}
+			number, _ := season["seasonNumber"].(float64)
+			if monitored, ok := update.SeasonMonitored[int(number)]; ok {
+				season["monitored"] = monitored
+			}
+		}
+	}
+
+	var updated Series
+	err := c.api.Put(path, query, raw, &updated)
+	return updated, err
+}
+
+// updateTags adds and removes tag IDs from the tags of a series as decoded from JSON
+func updateTags(current any, add, remove []int) []int {
+	var tags []int
+	values, _ := current.([]any)
+	for _, value := range values {
+		if id, ok := value.(float64); ok && !slices.Contains(remove, int(id)) {
+			tags = append(tags, int(id))
+		}
+	}
+	for _, id := range add {
+		if !slices.Contains(tags, id) {
+			tags = append(tags, id)
+		}
+	}
+	return tags
+}
+
+// pathBase returns the last element of a Unix or Windows path, as Sonarr may run on either
+func pathBase(p string) string {
+	p = strings.TrimRight(p, `/\`)
+	return p[strings.LastIndexAny(p, `/\`)+1:]
+}
+
+// joinPath appends name to a root folder path using the separator the root folder uses
+func joinPath(root, name string) string {
+	separator := "/"
+	if strings.Contains(root, `\`) {
+		separator = `\`
+	}
+	return strings.TrimRight(root, `/\`) + separator + name
+}
+
+// DeleteSeries removes a series from Sonarr, optionally deleting its files and excluding it from import lists
+func (c *Client) DeleteSeries(id int, deleteFiles, addImportListExclusion bool) error {
+	query := url.Values{
+		"deleteFiles":            {strconv.FormatBool(deleteFiles)},
+		"addImportListExclusion": {strconv.FormatBool(addImportListExclusion)},
+	}
+	return c.api.Delete(fmt.Sprintf("/series/%d", id), query)
+}
+
+// QualityProfiles returns the quality profiles a series can be assigned
+func (c *Client) QualityProfiles() ([]QualityProfile, error) {
+	var profiles []QualityProfile
+	err := c.api.Get("/qualityprofile", nil, &profiles)
+	return profiles, err
+}
+
+// LanguageProfiles returns the language profiles, ErrNotFound on Sonarr v4 which has none
+func (c *Client) LanguageProfiles() ([]LanguageProfile, error) {
+	var profiles []LanguageProfile
+	err := c.api.Get("/languageprofile", nil, &profiles)
+	return profiles, err
+}
+
+// RootFolders returns the folders series can be stored in
+func (c *Client) RootFolders() ([]RootFolder, error) {
+	var folders []RootFolder
+	err := c.api.Get("/rootfolder", nil, &folders)
+	return folders, err
+}
+
+// Tags returns the tags that can be put on series
+func (c *Client) Tags() ([]Tag, error) {
+	var tags []Tag
+	err := c.api.Get("/tag", nil, &tags)
+	return tags, err
+}
+
+// CreateTag adds a tag, Sonarr stores the label in lowercase
+func (c *Client) CreateTag(label string) (Tag, error) {
+	var tag Tag
+	err := c.api.Post("/tag", nil, Tag{Label: label}, &tag)
+	return tag, err
+}
+
+// Calendar returns the monitored episodes airing between start and end, with their series
+func (c *Client) Calendar(start, end time.Time) ([]Episode, error) {
+	query := url.Values{
+		"start":         {start.UTC().Format(time.RFC3339)},
+		"end":           {end.UTC().Format(time.RFC3339)},
+		"includeSeries": {"true"},
+	}
+
+	var episodes []Episode
+	err := c.api.Get("/calendar", query, &episodes)
+	return episodes, err
+}
+
+// Queue returns a page of the download queue with the series and episode of each item
+func (c *Client) Queue(page, pageSize int) (QueuePage, error) {
+	query := url.Values{
+		"page":           {strconv.Itoa(page)},
+		"pageSize":       {strconv.Itoa(pageSize)},
+		"includeSeries":  {"true"},
+		"includeEpisode": {"true"},
+	}
+
+	var queue QueuePage
+	err := c.api.Get("/queue", query, &queue)
+	return queue, err
+}
+
+// RemoveFromQueue removes an item from the queue and the download client, optionally blocklisting the release
+func (c *Client) RemoveFromQueue(id int, blocklist bool) error {
+	query := url.Values{
+		"removeFromClient": {"true"},
+		"blocklist":        {strconv.FormatBool(blocklist)},
+	}
+	return c.api.Delete(fmt.Sprintf("/queue/%d", id), query)
+}
+
+// MissingEpisodes returns a page of the monitored episodes that have aired but have no file, newest first
+func (c *Client) MissingEpisodes(page, pageSize int) (EpisodePage, error) {
+	query := url.Values{
+		"page":          {strconv.Itoa(page)},
+		"pageSize":      {strconv.Itoa(pageSize)},
+		"includeSeries": {"true"},
+		"monitored":     {"true"},
+		"sortKey":       {"airDateUtc"},
+		"sortDirection": {"descending"},
+	}
+
+	var missing EpisodePage
+	err := c.api.Get("/wanted/missing", query, &missing)
+	return missing, err
+}
+
+// Episodes returns every episode of a series
+func (c *Client) Episodes(seriesID int) ([]Episode, error) {
+	var episodes []Episode
+	err := c.api.Get("/episode", url.Values{"seriesId": {strconv.Itoa(seriesID)}}, &episodes)
+	return episodes, err
+}
+
+// History returns a page of the history, newest first
+func (c *Client) History(page, pageSize int) (HistoryPage, error) {
+	query := url.Values{
+		"page":           {strconv.Itoa(page)},
+		"pageSize":       {strconv.Itoa(pageSize)},
+		"includeSeries":  {"true"},
+		"includeEpisode": {"true"},
+		"sortKey":        {"date"},
+		"sortDirection":  {"descending"},
+	}
+
+	var history HistoryPage
+	err := c.api.Get("/history", query, &history)
+	return history, err
+}
+
+// HistorySince returns the history recorded after the given time
+func (c *Client) HistorySince(since time.Time) ([]HistoryRecord, error) {
+	query := url.Values{
+		"date":           {since.UTC().Format(time.RFC3339)},
+		"includeSeries":  {"true"},
+		"includeEpisode": {"true"},
+	}
+
+	var history []HistoryRecord
+	err := c.api.Get("/history/since", query, &history)
+	return history, err
+}
+
+// SeriesHistory returns the history of a series
+func (c *Client) SeriesHistory(seriesID int) ([]HistoryRecord, error) {
+	query := url.Values{
+		"seriesId":       {strconv.Itoa(seriesID)},
+		"includeSeries":  {"true"},
+		"includeEpisode": {"true"},
+	}
+
+	var history []HistoryRecord
+	err := c.api.Get("/history/series", query, &history)
+	return history, err
+}
+
+// SystemStatus returns the version and start time of Sonarr
+func (c *Client) SystemStatus() (SystemStatus, error) {
+	var status SystemStatus
+	err := c.api.Get("/system/status", nil, &status)
+	return status, err
+}
+
+// Health returns the issues currently reported by the Sonarr health checks
+func (c *Client) Health() ([]HealthCheck, error) {
+	var checks []HealthCheck
+	err := c.api.Get("/health", nil, &checks)
+	return checks, err
+}
+
+// DiskSpace returns the free and total space of the disks Sonarr can see
+func (c *Client) DiskSpace() ([]DiskSpace, error) {
+	var disks []DiskSpace
+	err := c.api.Get("/diskspace", nil, &disks)
+	return disks, err
+}
+
+// Releases searches the indexers for the releases of an episode, in Sonarr's order of preference
+func (c *Client) Releases(episodeID int) ([]Release, error) {
+	var releases []Release
+	err := c.search.Get("/release", url.Values{"episodeId": {strconv.Itoa(episodeID)}}, &releases)
+	return releases, err
+}
+
+// GrabRelease sends a release found by Releases to the download client
+func (c *Client) GrabRelease(guid string, indexerID int) error {
+	body := map[string]any{"guid": guid, "indexerId": indexerID}
+	return c.api.Post("/release", nil, body, nil)
+}
+
+// RunCommand queues a command such as EpisodeSearch
+func (c *Client) RunCommand(command NewCommand) (Command, error) {
+	var queued Command
+	err := c.api.Post("/command", nil, command, &queued)
+	return queued, err
+}
+
+// CommandStatus returns the current state of a queued command
+func (c *Client) CommandStatus(id int) (Command, error) {
+	var command Command
+	err := c.api.Get(fmt.Sprintf("/command/%d", id), nil, &command)
+	return command, err
+}
@@ -0,0 +1,174 @@
+package sonarr
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// storedSeries is the series the test server holds, with fields Series does not model
+const storedSeries = `{
+	"id": 7,
+	"title": "Severance",
+	"path": "/tv/Severance",
+	"rootFolderPath": "/tv",
+	"monitored": true,
+	"qualityProfileId": 1,
+	"tags": [1, 2, 3],
+	"seasons": [
+		{"seasonNumber": 1, "monitored": true, "statistics": {"episodeCount": 9}},
+		{"seasonNumber": 2, "monitored": true, "statistics": {"episodeCount": 10}}
+	],
+	"ratings": {"votes": 1234, "value": 8.7},
+	"addOptions": {"ignoreEpisodesWithFiles": true}
+}`
+
+// putRequest is the series sent back by UpdateSeries
+type putRequest struct {
+	body  map[string]any
+	query string
+}
+
+// newUpdateServer returns a client for a server holding storedSeries, recording the PUT request it receives
+func newUpdateServer(t *testing.T) (*Client, *putRequest) {
+	put := &putRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/series/7" {
+			t.Errorf("request path = %s, want /api/v3/series/7", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(storedSeries))
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			if err := json.Unmarshal(body, &put.body); err != nil {
+				t.Errorf("PUT body is not JSON: %v", err)
+			}
+			put.query = r.URL.RawQuery
+			w.Write(body)
+		default:
+			t.Errorf("unexpected %s request", r.Method)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return NewClient(Config{BaseURL: server.URL, APIKey: "key", Timeout: time.Second}), put
+}
+
+// stored returns storedSeries decoded as UpdateSeries sees it
+func stored(t *testing.T) map[string]any {
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(storedSeries), &raw); err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestUpdateSeries(t *testing.T) {
+	off := false
+	profile := 4
+
+	tests := []struct {
+		name      string
+		update    SeriesUpdate
+		change    func(raw map[string]any) // the expected changes to the stored series
+		wantQuery string
+	}{
+		{
+			name:   "monitored and quality profile",
+			update: SeriesUpdate{Monitored: &off, QualityProfileID: &profile},
+			change: func(raw map[string]any) {
+				raw["monitored"] = false
+				raw["qualityProfileId"] = float64(4)
+			},
+		},
+		{
+			name:   "tags added and removed",
+			update: SeriesUpdate{AddTags: []int{3, 4, 4}, RemoveTags: []int{2, 5}},
+			change: func(raw map[string]any) {
+				raw["tags"] = []any{float64(1), float64(3), float64(4)}
+			},
+		},
+		{
+			name:   "root folder",
+			update: SeriesUpdate{RootFolderPath: "/media/tv2/"},
+			change: func(raw map[string]any) {
+				raw["rootFolderPath"] = "/media/tv2/"
+				raw["path"] = "/media/tv2/Severance"
+			},
+			wantQuery: "moveFiles=true",
+		},
+		{
+			name:   "season",
+			update: SeriesUpdate{SeasonMonitored: map[int]bool{2: false, 9: false}},
+			change: func(raw map[string]any) {
+				seasons := raw["seasons"].([]any)
+				seasons[1].(map[string]any)["monitored"] = false
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, put := newUpdateServer(t)
+			if _, err := client.UpdateSeries(7, test.update); err != nil {
+				t.Fatalf("UpdateSeries() error = %v", err)
+			}
+
+			// Everything but the changed fields is sent back as it was, including the fields Series does not model
+			want := stored(t)
+			test.change(want)
+			if !reflect.DeepEqual(put.body, want) {
+				t.Errorf("PUT body = %v\nwant %v", put.body, want)
+			}
+			if put.query != test.wantQuery {
+				t.Errorf("PUT query = %q, want %q", put.query, test.wantQuery)
+			}
+		})
+	}
+}
+
+func TestUpdateTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		current any
+		add     []int
+		remove  []int
+		want    []int
+	}{
+		{"add", []any{float64(1)}, []int{2}, nil, []int{1, 2}},
+		{"add twice", []any{float64(1)}, []int{1, 2, 2}, nil, []int{1, 2}},
+		{"remove", []any{float64(1), float64(2)}, nil, []int{1, 3}, []int{2}},
+		{"remove then add", []any{float64(1), float64(2)}, []int{2}, []int{2}, []int{1, 2}},
+		{"no tags", nil, []int{5}, nil, []int{5}},
+		{"remove the last", []any{float64(1)}, nil, []int{1}, nil},
+	}
+
+	for _, test := range tests {
+		if got := updateTags(test.current, test.add, test.remove); !slices.Equal(got, test.want) {
+			t.Errorf("%s: updateTags() = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		root, path string
+		want       string
+	}{
+		{"/media/tv", "/tv/Severance", "/media/tv/Severance"},
+		{"/media/tv/", "/tv/Severance/", "/media/tv/Severance"},
+		{`D:\TV`, `C:\Shows\Severance`, `D:\TV\Severance`},
+		{`D:\TV\`, "/tv/Severance", `D:\TV\Severance`},
+	}
+
+	for _, test := range tests {
+		if got := joinPath(test.root, pathBase(test.path)); got != test.want {
+			t.Errorf("joinPath(%q, pathBase(%q)) = %q, want %q", test.root, test.path, got, test.want)
+		}
+	}
+}
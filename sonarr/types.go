@@ -0,0 +1,321 @@
+package sonarr
+
+import (
+	"fmt"
+	"time"
+)
+
+// Series represents a series as returned by the Sonarr series and lookup endpoints
+type Series struct {
+	ID               int              `json:"id"` // zero for lookup results not yet added to Sonarr
+	Title            string           `json:"title"`
+	AlternateTitles  []AlternateTitle `json:"alternateTitles"`
+	TitleSlug        string           `json:"titleSlug"`
+	TvdbID           int              `json:"tvdbId"`
+	ImdbID           string           `json:"imdbId"`
+	Year             int              `json:"year"`
+	Network          string           `json:"network"`
+	Overview         string           `json:"overview"`
+	Status           string           `json:"status"`
+	Genres           []string         `json:"genres"`
+	Runtime          int              `json:"runtime"`
+	Monitored        bool             `json:"monitored"`
+	NextAiring       *time.Time       `json:"nextAiring"`
+	PreviousAiring   *time.Time       `json:"previousAiring"`
+	QualityProfileID int              `json:"qualityProfileId"`
+	Path             string           `json:"path"`
+	RootFolderPath   string           `json:"rootFolderPath"`
+	Tags             []int            `json:"tags"`
+	Images           []Image          `json:"images"`
+	Seasons          []Season         `json:"seasons"`
+	Statistics       SeriesStatistics `json:"statistics"`
+}
+
+// Poster returns the URL of the series poster, empty when there is none
+func (s Series) Poster() string {
+	for _, image := range s.Images {
+		if image.CoverType == "poster" {
+			if image.RemoteURL != "" {
+				return image.RemoteURL
+			}
+			return image.URL
+		}
+	}
+	return ""
+}
+
+// AlternateTitle is another title a series is known by, e.g. its original or a regional title
+type AlternateTitle struct {
+	Title        string `json:"title"`
+	SeasonNumber *int   `json:"seasonNumber"` // set when the title only applies to one season
+}
+
+// SeriesStatistics holds the episode and file counts of a series
+type SeriesStatistics struct {
+	SeasonCount       int     `json:"seasonCount"`
+	EpisodeFileCount  int     `json:"episodeFileCount"`
+	EpisodeCount      int     `json:"episodeCount"`
+	TotalEpisodeCount int     `json:"totalEpisodeCount"`
+	SizeOnDisk        int64   `json:"sizeOnDisk"`
+	PercentOfEpisodes float64 `json:"percentOfEpisodes"`
+}
+
+// Season is a season of a series and whether it is monitored, Statistics is nil in lookup results
+type Season struct {
+	SeasonNumber int               `json:"seasonNumber"`
+	Monitored    bool              `json:"monitored"`
+	Statistics   *SeasonStatistics `json:"statistics,omitempty"`
+}
+
+// SeasonStatistics holds the episode and file counts of a season
+type SeasonStatistics struct {
+	EpisodeFileCount  int        `json:"episodeFileCount"`
+	EpisodeCount      int        `json:"episodeCount"`
+	TotalEpisodeCount int        `json:"totalEpisodeCount"`
+	SizeOnDisk        int64      `json:"sizeOnDisk"`
+	NextAiring        *time.Time `json:"nextAiring"`
+}
+
+// Image is a poster, banner or fanart image of a series
+type Image struct {
+	CoverType string `json:"coverType"`
+	URL       string `json:"url"`
+	RemoteURL string `json:"remoteUrl"`
+}
+
+// QualityProfile is a Sonarr quality profile
+type QualityProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// LanguageProfile is a Sonarr v3 language profile (removed in Sonarr v4)
+type LanguageProfile struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// RootFolder is a folder Sonarr stores series in
+type RootFolder struct {
+	ID         int    `json:"id"`
+	Path       string `json:"path"`
+	Accessible bool   `json:"accessible"`
+	FreeSpace  int64  `json:"freeSpace"`
+}
+
+// Tag is a label put on series, used to link them to indexers, download clients and notifications
+type Tag struct {
+	ID    int    `json:"id,omitempty"`
+	Label string `json:"label"`
+}
+
+// MonitorModes are the values accepted by AddOptions.Monitor, in the order shown to users
+var MonitorModes = []string{"all", "future", "missing", "existing", "pilot", "firstSeason", "latestSeason", "none"}
+
+// AddOptions controls which episodes are monitored and searched for when a series is added
+type AddOptions struct {
+	Monitor                  string `json:"monitor"`
+	SearchForMissingEpisodes bool   `json:"searchForMissingEpisodes"`
+}
+
+// NewSeries is the body of an add series request, built from a lookup result
+type NewSeries struct {
+	Title             string     `json:"title"`
+	TitleSlug         string     `json:"titleSlug"`
+	TvdbID            int        `json:"tvdbId"`
+	Year              int        `json:"year"`
+	Images            []Image    `json:"images"`
+	Seasons           []Season   `json:"seasons"`
+	QualityProfileID  int        `json:"qualityProfileId"`
+	LanguageProfileID int        `json:"languageProfileId,omitempty"`
+	RootFolderPath    string     `json:"rootFolderPath"`
+	Monitored         bool       `json:"monitored"`
+	SeasonFolder      bool       `json:"seasonFolder"`
+	AddOptions        AddOptions `json:"addOptions"`
+}
+
+// Episode is an episode of a series, Series is only filled when requested (e.g. by the calendar)
+type Episode struct {
+	ID            int       `json:"id"`
+	SeriesID      int       `json:"seriesId"`
+	SeasonNumber  int       `json:"seasonNumber"`
+	EpisodeNumber int       `json:"episodeNumber"`
+	Title         string    `json:"title"`
+	AirDateUtc    time.Time `json:"airDateUtc"`
+	HasFile       bool      `json:"hasFile"`
+	Monitored     bool      `json:"monitored"`
+	Series        *Series   `json:"series"`
+}
+
+// Code returns the season and episode number as SxxEyy
+func (e Episode) Code() string {
+	return fmt.Sprintf("S%02dE%02d", e.SeasonNumber, e.EpisodeNumber)
+}
+
+// QueueItem is a download in progress or waiting for import
+type QueueItem struct {
+	ID                    int             `json:"id"`
+	SeriesID              int             `json:"seriesId"`
+	EpisodeID             int             `json:"episodeId"`
+	Title                 string          `json:"title"`
+	Size                  float64         `json:"size"`
+	SizeLeft              float64         `json:"sizeleft"`
+	TimeLeft              string          `json:"timeleft"` // e.g. 00:12:34, empty when unknown
+	Status                string          `json:"status"`
+	TrackedDownloadStatus string          `json:"trackedDownloadStatus"` // ok, warning or error
+	TrackedDownloadState  string          `json:"trackedDownloadState"`
+	StatusMessages        []StatusMessage `json:"statusMessages"`
+	ErrorMessage          string          `json:"errorMessage"`
+	DownloadClient        string          `json:"downloadClient"`
+	Protocol              string          `json:"protocol"`
+	Series                *Series         `json:"series"`
+	Episode               *Episode        `json:"episode"`
+}
+
+// Progress returns the downloaded percentage of the item
+func (q QueueItem) Progress() float64 {
+	if q.Size <= 0 {
+		return 0
+	}
+	return (q.Size - q.SizeLeft) / q.Size * 100
+}
+
+// StatusMessage is a warning or error Sonarr reports for a queue item
+type StatusMessage struct {
+	Title    string   `json:"title"`
+	Messages []string `json:"messages"`
+}
+
+// QueuePage is a page of the download queue
+type QueuePage struct {
+	Page         int         `json:"page"`
+	PageSize     int         `json:"pageSize"`
+	TotalRecords int         `json:"totalRecords"`
+	Records      []QueueItem `json:"records"`
+}
+
+// EpisodePage is a page of episodes, as returned by the wanted/missing endpoint
+type EpisodePage struct {
+	Page         int       `json:"page"`
+	PageSize     int       `json:"pageSize"`
+	TotalRecords int       `json:"totalRecords"`
+	Records      []Episode `json:"records"`
+}
+
+// NewCommand is the body of a command request, only the fields used by the named command are sent
+type NewCommand struct {
+	Name       string `json:"name"` // e.g. EpisodeSearch, SeriesSearch
+	SeriesID   int    `json:"seriesId,omitempty"`
+	EpisodeIDs []int  `json:"episodeIds,omitempty"`
+}
+
+// Command is a command queued or run by Sonarr
+type Command struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name"`
+	Status  string    `json:"status"` // queued, started, completed, failed, aborted or cancelled
+	Message string    `json:"message"`
+	Queued  time.Time `json:"queued"`
+	Started time.Time `json:"started"`
+	Ended   time.Time `json:"ended"`
+}
+
+// Finished reports whether the command has stopped running, successfully or not
+func (c Command) Finished() bool {
+	switch c.Status {
+	case "completed", "failed", "aborted", "cancelled":
+		return true
+	}
+	return false
+}
+
+// SeriesUpdate lists the changes applied by UpdateSeries, nil and empty fields are left unchanged
+type SeriesUpdate struct {
+	Monitored        *bool
+	SeasonMonitored  map[int]bool // season number to monitored state
+	QualityProfileID *int
+	AddTags          []int
+	RemoveTags       []int
+	RootFolderPath   string // moves the series folder and its files into this root folder
+}
+
+// Quality is the quality of a release or episode file, e.g. WEBDL-1080p
+type Quality struct {
+	Quality struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"quality"`
+}
+
+// Name returns the quality name
+func (q Quality) Name() string {
+	return q.Quality.Name
+}
+
+// HistoryRecord is an event in the Sonarr history, such as a grab or an import.
+// Data holds event specific details such as indexer, releaseGroup, message and reason.
+type HistoryRecord struct {
+	ID          int               `json:"id"`
+	EpisodeID   int               `json:"episodeId"`
+	SeriesID    int               `json:"seriesId"`
+	SourceTitle string            `json:"sourceTitle"`
+	Quality     Quality           `json:"quality"`
+	Date        time.Time         `json:"date"`
+	EventType   string            `json:"eventType"` // e.g. grabbed, downloadFolderImported, downloadFailed, episodeFileDeleted
+	Data        map[string]string `json:"data"`
+	Episode     *Episode          `json:"episode"`
+	Series      *Series           `json:"series"`
+}
+
+// HistoryPage is a page of the history
+type HistoryPage struct {
+	Page         int             `json:"page"`
+	PageSize     int             `json:"pageSize"`
+	TotalRecords int             `json:"totalRecords"`
+	Records      []HistoryRecord `json:"records"`
+}
+
+// SystemStatus is the version and runtime information of a Sonarr instance
+type SystemStatus struct {
+	AppName      string    `json:"appName"`
+	InstanceName string    `json:"instanceName"`
+	Version      string    `json:"version"`
+	Branch       string    `json:"branch"`
+	OsName       string    `json:"osName"`
+	StartTime    time.Time `json:"startTime"`
+}
+
+// HealthCheck is an issue reported by the Sonarr health checks
+type HealthCheck struct {
+	Source  string `json:"source"`
+	Type    string `json:"type"` // ok, notice, warning or error
+	Message string `json:"message"`
+	WikiURL string `json:"wikiUrl"`
+}
+
+// DiskSpace is the free and total space of a disk
+type DiskSpace struct {
+	Path       string `json:"path"`
+	Label      string `json:"label"`
+	FreeSpace  int64  `json:"freeSpace"`
+	TotalSpace int64  `json:"totalSpace"`
+}
+
+// Release is a release found on an indexer by an episode search
+type Release struct {
+	GUID          string   `json:"guid"`
+	Title         string   `json:"title"`
+	Quality       Quality  `json:"quality"`
+	Size          int64    `json:"size"`
+	AgeHours      float64  `json:"ageHours"`
+	Indexer       string   `json:"indexer"`
+	IndexerID     int      `json:"indexerId"`
+	ReleaseGroup  string   `json:"releaseGroup"`
+	Protocol      string   `json:"protocol"` // torrent or usenet
+	Seeders       *int     `json:"seeders"`  // nil for usenet
+	Leechers      *int     `json:"leechers"`
+	Approved      bool     `json:"approved"`
+	Rejected      bool     `json:"rejected"`
+	Rejections    []string `json:"rejections"`
+	ReleaseWeight int      `json:"releaseWeight"` // position in Sonarr's order of preference
+}
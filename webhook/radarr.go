@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// RadarrEvent is the payload Radarr posts to a Webhook connection
+type RadarrEvent struct {
+	EventType      string        `json:"eventType"`
+	InstanceName   string        `json:"instanceName"`
+	Movie          RadarrMovie   `json:"movie"`
+	Release        RadarrRelease `json:"release"`
+	MovieFile      RadarrFile    `json:"movieFile"`
+	DownloadClient string        `json:"downloadClient"`
+	IsUpgrade      bool          `json:"isUpgrade"`
+	DeletedFiles   any           `json:"deletedFiles"` // list of files on upgrade, bool on movie delete
+
+	// Health events
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	WikiURL string `json:"wikiUrl"`
+}
+
+// RadarrMovie is the movie an event applies to
+type RadarrMovie struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Year        int    `json:"year"`
+	FolderPath  string `json:"folderPath"`
+	TmdbID      int    `json:"tmdbId"`
+	ImdbID      string `json:"imdbId"`
+	ReleaseDate string `json:"releaseDate"`
+}
+
+// RadarrRelease is the release grabbed by a Grab event
+type RadarrRelease struct {
+	Quality      string `json:"quality"`
+	ReleaseGroup string `json:"releaseGroup"`
+	ReleaseTitle string `json:"releaseTitle"`
+	Indexer      string `json:"indexer"`
+	Size         int64  `json:"size"`
+}
+
+// RadarrFile is the file imported by a Download event or removed by a MovieFileDelete event
+type RadarrFile struct {
+	RelativePath string `json:"relativePath"`
+	Quality      string `json:"quality"`
+	ReleaseGroup string `json:"releaseGroup"`
+	Size         int64  `json:"size"`
+}
+
+// Kind returns the event name used to pick the channel: grab, download, upgrade, rename,
+// moviedelete, moviefiledelete, health, test... Radarr reports upgrades as downloads with isUpgrade set.
+func (e RadarrEvent) Kind() string {
+	if e.EventType == "Download" && e.IsUpgrade {
+		return "upgrade"
+	}
+	return strings.ToLower(e.EventType)
+}
+
+// Embed formats the event for Discord
+func (e RadarrEvent) Embed() *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:  e.Movie.Title,
+		Color:  colorInfo,
+		Footer: &discordgo.MessageEmbedFooter{Text: "Radarr"},
+	}
+	if e.Movie.Year != 0 {
+		embed.Title = fmt.Sprintf("%s (%d)", e.Movie.Title, e.Movie.Year)
+	}
+	if e.InstanceName != "" {
+		embed.Footer.Text = e.InstanceName
+	}
+
+	switch e.Kind() {
+	case "grab":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "📥 Grabbed"}
+		embed.Fields = nonEmptyFields(
+			field("Quality", e.Release.Quality),
+			field("Release group", e.Release.ReleaseGroup),
+			field("Indexer", e.Release.Indexer),
+			sizeField(e.Release.Size),
+			field("Download client", e.DownloadClient),
+			wideField("Release", e.Release.ReleaseTitle),
+		)
+	case "download", "upgrade":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "✅ Downloaded"}
+		embed.Color = colorSuccess
+		if e.Kind() == "upgrade" {
+			embed.Author.Name = "⬆️ Upgraded"
+		}
+		embed.Fields = nonEmptyFields(
+			field("Quality", e.MovieFile.Quality),
+			field("Release group", e.MovieFile.ReleaseGroup),
+			sizeField(e.MovieFile.Size),
+			wideField("File", e.MovieFile.RelativePath),
+		)
+	case "rename":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "✏️ Renamed"}
+		embed.Description = "Movie files were renamed."
+	case "moviedelete":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "🗑️ Movie deleted"}
+		embed.Color = colorDanger
+		embed.Description = fmt.Sprintf("Removed from Radarr, files deleted: %v", e.DeletedFiles == true)
+	case "moviefiledelete":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "🗑️ Movie file deleted"}
+		embed.Color = colorWarning
+		embed.Fields = nonEmptyFields(
+			field("Quality", e.MovieFile.Quality),
+			wideField("File", e.MovieFile.RelativePath),
+		)
+	case "health":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "🩺 Health check"}
+		embed.Title = e.Type
+		embed.Description = e.Message
+		embed.URL = e.WikiURL
+		embed.Color = colorWarning
+		if e.Level == "error" {
+			embed.Color = colorDanger
+		}
+	case "test":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "🔔 Test"}
+		embed.Description = "Radarr webhook connection test received."
+	default:
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: e.EventType}
+	}
+
+	return embed
+}
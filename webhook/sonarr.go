@@ -0,0 +1,152 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Embed colours by kind of event
+const (
+	colorInfo    = 0x3498db
+	colorSuccess = 0x2ecc71
+	colorWarning = 0xf1c40f
+	colorDanger  = 0xe74c3c
+)
+
+// SonarrEvent is the payload Sonarr posts to a Webhook connection
+type SonarrEvent struct {
+	EventType      string          `json:"eventType"`
+	InstanceName   string          `json:"instanceName"`
+	Series         SonarrSeries    `json:"series"`
+	Episodes       []SonarrEpisode `json:"episodes"`
+	Release        SonarrRelease   `json:"release"`
+	EpisodeFile    SonarrFile      `json:"episodeFile"`
+	DownloadClient string          `json:"downloadClient"`
+	IsUpgrade      bool            `json:"isUpgrade"`
+	DeletedFiles   any             `json:"deletedFiles"` // list of files on upgrade, bool on series delete
+
+	// Health events
+	Level   string `json:"level"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	WikiURL string `json:"wikiUrl"`
+}
+
+// SonarrSeries is the series an event applies to
+type SonarrSeries struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	Path   string `json:"path"`
+	TvdbID int    `json:"tvdbId"`
+	Year   int    `json:"year"`
+}
+
+// SonarrEpisode is an episode an event applies to
+type SonarrEpisode struct {
+	ID            int    `json:"id"`
+	SeasonNumber  int    `json:"seasonNumber"`
+	EpisodeNumber int    `json:"episodeNumber"`
+	Title         string `json:"title"`
+	AirDate       string `json:"airDate"`
+}
+
+// SonarrRelease is the release grabbed by a Grab event
+type SonarrRelease struct {
+	Quality      string `json:"quality"`
+	ReleaseGroup string `json:"releaseGroup"`
+	ReleaseTitle string `json:"releaseTitle"`
+	Indexer      string `json:"indexer"`
+	Size         int64  `json:"size"`
+}
+
+// SonarrFile is the file imported by a Download event
+type SonarrFile struct {
+	RelativePath string `json:"relativePath"`
+	Quality      string `json:"quality"`
+	ReleaseGroup string `json:"releaseGroup"`
+	Size         int64  `json:"size"`
+}
+
+// Kind returns the event name used to pick the channel: grab, download, upgrade, rename,
+// seriesdelete, health, test... Sonarr reports upgrades as downloads with isUpgrade set.
+func (e SonarrEvent) Kind() string {
+	if e.EventType == "Download" && e.IsUpgrade {
+		return "upgrade"
+	}
+	return strings.ToLower(e.EventType)
+}
+
+// Embed formats the event for Discord
+func (e SonarrEvent) Embed() *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:  e.Series.Title,
+		Color:  colorInfo,
+		Footer: &discordgo.MessageEmbedFooter{Text: "Sonarr"},
+	}
+	if e.InstanceName != "" {
+		embed.Footer.Text = e.InstanceName
+	}
+
+	if episodes := e.episodeList(); episodes != "" {
+		embed.Description = episodes
+	}
+
+	switch e.Kind() {
+	case "grab":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "📥 Grabbed"}
+		embed.Fields = nonEmptyFields(
+			field("Quality", e.Release.Quality),
+			field("Release group", e.Release.ReleaseGroup),
+			field("Indexer", e.Release.Indexer),
+			sizeField(e.Release.Size),
+			field("Download client", e.DownloadClient),
+			wideField("Release", e.Release.ReleaseTitle),
+		)
+	case "download", "upgrade":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "✅ Downloaded"}
+		embed.Color = colorSuccess
+		if e.Kind() == "upgrade" {
+			embed.Author.Name = "⬆️ Upgraded"
+		}
+		embed.Fields = nonEmptyFields(
+			field("Quality", e.EpisodeFile.Quality),
+			field("Release group", e.EpisodeFile.ReleaseGroup),
+			sizeField(e.EpisodeFile.Size),
+			wideField("File", e.EpisodeFile.RelativePath),
+		)
+	case "rename":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "✏️ Renamed"}
+		embed.Description = "Episode files were renamed."
+	case "seriesdelete":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "🗑️ Series deleted"}
+		embed.Color = colorDanger
+		embed.Description = fmt.Sprintf("Removed from Sonarr, files deleted: %v", e.DeletedFiles == true)
+	case "health":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "🩺 Health check"}
+		embed.Title = e.Type
+		embed.Description = e.Message
+		embed.URL = e.WikiURL
+		embed.Color = colorWarning
+		if e.Level == "error" {
+			embed.Color = colorDanger
+		}
+	case "test":
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: "🔔 Test"}
+		embed.Description = "Sonarr webhook connection test received."
+	default:
+		embed.Author = &discordgo.MessageEmbedAuthor{Name: e.EventType}
+	}
+
+	return embed
+}
+
+// episodeList returns one line per episode, e.g. "S01E02 - Title"
+func (e SonarrEvent) episodeList() string {
+	var lines []string
+	for _, episode := range e.Episodes {
+		lines = append(lines, fmt.Sprintf("S%02dE%02d - %s", episode.SeasonNumber, episode.EpisodeNumber, episode.Title))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,228 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"log"
+	"main/auth"
+	"main/format"
+	"net/http"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxPayloadSize caps the size of an accepted webhook payload
+const maxPayloadSize = 1 << 20
+
+// Timeouts of the listener, which may be reachable from the internet
+const (
+	readHeaderTimeout = 10 * time.Second
+	readTimeout       = 30 * time.Second
+	writeTimeout      = 30 * time.Second
+	idleTimeout       = 2 * time.Minute
+)
+
+// Notifier posts an embed to a Discord channel
+type Notifier func(channelID string, embed *discordgo.MessageEmbed) error
+
+// Server receives the Webhook connection payloads of the *arr apps and posts them to Discord
+type Server struct {
+	config   auth.WebhookConfig
+	secret   string
+	notify   Notifier
+	onSonarr []func(SonarrEvent)
+	onRadarr []func(RadarrEvent)
+}
+
+// NewServer creates a webhook server posting events with notify.
+// Requests must carry secret as the basic auth password, the X-Webhook-Secret header or the secret query parameter.
+func NewServer(config auth.WebhookConfig, secret string, notify Notifier) *Server {
+	return &Server{config: config, secret: secret, notify: notify}
+}
+
+// OnSonarrEvent registers a function called for every Sonarr event received, even when posting it fails
+func (srv *Server) OnSonarrEvent(listener func(SonarrEvent)) {
+	srv.onSonarr = append(srv.onSonarr, listener)
+}
+
+// OnRadarrEvent registers a function called for every Radarr event received, even when posting it fails
+func (srv *Server) OnRadarrEvent(listener func(RadarrEvent)) {
+	srv.onRadarr = append(srv.onRadarr, listener)
+}
+
+// Handler returns the HTTP handler serving the webhook endpoints
+func (srv *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /sonarr", srv.handleSonarr)
+	mux.HandleFunc("POST /radarr", srv.handleRadarr)
+	return mux
+}
+
+// ListenAndServe listens on the configured address until the listener fails
+func (srv *Server) ListenAndServe() error {
+	server := &http.Server{
+		Addr:              srv.config.Listen,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+	log.Println("Webhook listener started on", srv.config.Listen)
+	return server.ListenAndServe()
+}
+
+// handleSonarr validates and posts a Sonarr event
+func (srv *Server) handleSonarr(w http.ResponseWriter, r *http.Request) {
+	var event SonarrEvent
+	if !srv.readEvent(w, r, &event) {
+		return
+	}
+	if event.EventType == "" {
+		log.Println("Webhook request rejected, Sonarr payload without eventType")
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Webhook received Sonarr %s event for %q", event.Kind(), event.Series.Title)
+
+	// The listeners refresh the caches, which must happen even when Discord is down
+	for _, listener := range srv.onSonarr {
+		listener(event)
+	}
+	if !srv.post(w, srv.channel(event.Kind()), event.Embed()) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRadarr validates and posts a Radarr event
+func (srv *Server) handleRadarr(w http.ResponseWriter, r *http.Request) {
+	var event RadarrEvent
+	if !srv.readEvent(w, r, &event) {
+		return
+	}
+	if event.EventType == "" {
+		log.Println("Webhook request rejected, Radarr payload without eventType")
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Webhook received Radarr %s event for %q", event.Kind(), event.Movie.Title)
+
+	for _, listener := range srv.onRadarr {
+		listener(event)
+	}
+
+	channelID, ok := srv.config.RadarrChannels[event.Kind()]
+	if !ok {
+		channelID = srv.channel(event.Kind())
+	}
+	if !srv.post(w, channelID, event.Embed()) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readEvent checks the secret of a request and decodes its JSON payload into event,
+// answering the request with an error when either fails
+func (srv *Server) readEvent(w http.ResponseWriter, r *http.Request, event any) bool {
+	if !srv.authorized(r) {
+		log.Println("Webhook request rejected, invalid secret from", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return false
+	}
+
+	if err := json.Unmarshal(body, event); err != nil {
+		log.Println("Webhook request rejected, invalid payload:", err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// post sends an event embed to a channel, events without a channel are accepted but not posted.
+// It answers the request with an error when Discord refuses the message.
+func (srv *Server) post(w http.ResponseWriter, channelID string, embed *discordgo.MessageEmbed) bool {
+	if channelID == "" {
+		return true
+	}
+	if err := srv.notify(channelID, embed); err != nil {
+		log.Println("Error posting webhook event to Discord:", err)
+		http.Error(w, "error posting to Discord", http.StatusBadGateway)
+		return false
+	}
+	return true
+}
+
+// authorized checks the shared secret of a request
+func (srv *Server) authorized(r *http.Request) bool {
+	if srv.secret == "" {
+		return false
+	}
+
+	candidates := []string{r.Header.Get("X-Webhook-Secret"), r.URL.Query().Get("secret")}
+	if _, password, ok := r.BasicAuth(); ok {
+		candidates = append(candidates, password)
+	}
+
+	for _, candidate := range candidates {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(srv.secret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// channel returns the channel configured for an event kind, or the default channel
+func (srv *Server) channel(kind string) string {
+	if channelID, ok := srv.config.Channels[kind]; ok {
+		return channelID
+	}
+	return srv.config.DefaultChannel
+}
+
+// field returns an inline embed field, or nil when value is empty
+func field(name, value string) *discordgo.MessageEmbedField {
+	if value == "" {
+		return nil
+	}
+	return &discordgo.MessageEmbedField{Name: name, Value: value, Inline: true}
+}
+
+// wideField returns a full width embed field, or nil when value is empty
+func wideField(name, value string) *discordgo.MessageEmbedField {
+	if value == "" {
+		return nil
+	}
+	return &discordgo.MessageEmbedField{Name: name, Value: value}
+}
+
+// sizeField returns an inline field with a size in bytes, or nil when the size is unknown
+func sizeField(size int64) *discordgo.MessageEmbedField {
+	if size <= 0 {
+		return nil
+	}
+	return field("Size", format.Bytes(size))
+}
+
+// nonEmptyFields drops the nil fields
+func nonEmptyFields(fields ...*discordgo.MessageEmbedField) []*discordgo.MessageEmbedField {
+	var result []*discordgo.MessageEmbedField
+	for _, f := range fields {
+		if f != nil {
+			result = append(result, f)
+		}
+	}
+	return result
+}
@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"main/auth"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const testSecret = "s3cret"
+
+// posted is an embed the server sent to Discord
+type posted struct {
+	channelID string
+	embed     *discordgo.MessageEmbed
+}
+
+// newTestServer returns a server recording the embeds it posts, with a channel for health events and for
+// Radarr downloads
+func newTestServer() (*Server, *[]posted) {
+	var sent []posted
+	config := auth.WebhookConfig{
+		DefaultChannel: "events",
+		Channels:       map[string]string{"health": "health"},
+		RadarrChannels: map[string]string{"download": "movies"},
+	}
+	server := NewServer(config, testSecret, func(channelID string, embed *discordgo.MessageEmbed) error {
+		sent = append(sent, posted{channelID, embed})
+		return nil
+	})
+	return server, &sent
+}
+
+// readFixture returns a recorded payload from testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	payload, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+// send posts a payload to path with the secret passed by withSecret and returns the response
+func send(t *testing.T, server *Server, path string, payload []byte, withSecret func(*http.Request)) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	if withSecret != nil {
+		withSecret(req)
+	}
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, req)
+	return recorder
+}
+
+// headerSecret passes the secret in the X-Webhook-Secret header
+func headerSecret(secret string) func(*http.Request) {
+	return func(req *http.Request) {
+		req.Header.Set("X-Webhook-Secret", secret)
+	}
+}
+
+func TestSecret(t *testing.T) {
+	tests := []struct {
+		name       string
+		withSecret func(*http.Request)
+		wantStatus int
+	}{
+		{"basic auth", func(req *http.Request) { req.SetBasicAuth("sonarr", testSecret) }, http.StatusNoContent},
+		{"header", headerSecret(testSecret), http.StatusNoContent},
+		{"query", func(req *http.Request) { req.URL.RawQuery = "secret=" + testSecret }, http.StatusNoContent},
+		{"wrong basic auth", func(req *http.Request) { req.SetBasicAuth("sonarr", "wrong") }, http.StatusUnauthorized},
+		{"wrong header", headerSecret("wrong"), http.StatusUnauthorized},
+		{"wrong query", func(req *http.Request) { req.URL.RawQuery = "secret=wrong" }, http.StatusUnauthorized},
+		{"no secret", nil, http.StatusUnauthorized},
+	}
+
+	payload := readFixture(t, "test.json")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, sent := newTestServer()
+			response := send(t, server, "/sonarr", payload, test.withSecret)
+			if response.Code != test.wantStatus {
+				t.Errorf("status = %d, want %d", response.Code, test.wantStatus)
+			}
+			if test.wantStatus != http.StatusNoContent && len(*sent) != 0 {
+				t.Errorf("rejected request posted %d embeds", len(*sent))
+			}
+		})
+	}
+}
+
+func TestEmptySecretRejectsEverything(t *testing.T) {
+	server := NewServer(auth.WebhookConfig{DefaultChannel: "events"}, "", func(string, *discordgo.MessageEmbed) error {
+		t.Error("request without a configured secret was posted")
+		return nil
+	})
+	response := send(t, server, "/sonarr", readFixture(t, "test.json"), headerSecret(""))
+	if response.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", response.Code, http.StatusUnauthorized)
+	}
+}
+
+// eventTest is a recorded payload and the embed it must be posted as
+type eventTest struct {
+	fixture     string
+	wantChannel string
+	wantTitle   string
+	wantAuthor  string
+	wantFields  map[string]string
+}
+
+// runEventTests posts each fixture to path and checks the embed posted for it
+func runEventTests(t *testing.T, path string, tests []eventTest) {
+	for _, test := range tests {
+		t.Run(test.fixture, func(t *testing.T) {
+			server, sent := newTestServer()
+			response := send(t, server, path, readFixture(t, test.fixture), headerSecret(testSecret))
+			if response.Code != http.StatusNoContent {
+				t.Fatalf("status = %d, want %d: %s", response.Code, http.StatusNoContent, response.Body)
+			}
+			if len(*sent) != 1 {
+				t.Fatalf("posted %d embeds, want 1", len(*sent))
+			}
+
+			got := (*sent)[0]
+			if got.channelID != test.wantChannel {
+				t.Errorf("channel = %q, want %q", got.channelID, test.wantChannel)
+			}
+			if got.embed.Title != test.wantTitle {
+				t.Errorf("title = %q, want %q", got.embed.Title, test.wantTitle)
+			}
+			if got.embed.Author == nil || got.embed.Author.Name != test.wantAuthor {
+				t.Errorf("author = %+v, want %q", got.embed.Author, test.wantAuthor)
+			}
+
+			fields := make(map[string]string)
+			for _, field := range got.embed.Fields {
+				if field.Value == "" {
+					t.Errorf("field %q is empty, Discord rejects the embed", field.Name)
+				}
+				fields[field.Name] = field.Value
+			}
+			for name, want := range test.wantFields {
+				if fields[name] != want {
+					t.Errorf("field %q = %q, want %q", name, fields[name], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSonarrEvents(t *testing.T) {
+	runEventTests(t, "/sonarr", []eventTest{
+		{
+			fixture: "grab.json", wantChannel: "events",
+			wantTitle: "Severance", wantAuthor: "📥 Grabbed",
+			wantFields: map[string]string{
+				"Quality": "WEBDL-1080p", "Release group": "NTb", "Indexer": "NZBgeek", "Size": "3.0 GB",
+				"Download client": "SABnzbd", "Release": "Severance.S02E01.Hello.Ms.Cobel.1080p.ATVP.WEB-DL.DDP5.1.H.264-NTb",
+			},
+		},
+		{
+			fixture: "download.json", wantChannel: "events",
+			wantTitle: "Severance", wantAuthor: "✅ Downloaded",
+			wantFields: map[string]string{
+				"Quality": "WEBDL-1080p", "Release group": "NTb", "Size": "3.0 GB",
+				"File": "Season 02/Severance - S02E01 - Hello, Ms. Cobel WEBDL-1080p.mkv",
+			},
+		},
+		{
+			fixture: "upgrade.json", wantChannel: "events",
+			wantTitle: "Severance", wantAuthor: "⬆️ Upgraded",
+			wantFields: map[string]string{"Quality": "WEBDL-2160p", "Release group": "FLUX", "Size": "9.0 GB"},
+		},
+		{
+			fixture: "rename.json", wantChannel: "events",
+			wantTitle: "Severance", wantAuthor: "✏️ Renamed",
+		},
+		{
+			fixture: "seriesdelete.json", wantChannel: "events",
+			wantTitle: "The Office (US)", wantAuthor: "🗑️ Series deleted",
+		},
+		{
+			fixture: "health.json", wantChannel: "health",
+			wantTitle: "IndexerLongTermStatusCheck", wantAuthor: "🩺 Health check",
+		},
+		{
+			fixture: "test.json", wantChannel: "events",
+			wantTitle: "Test Title", wantAuthor: "🔔 Test",
+		},
+	})
+}
+
+func TestRadarrEvents(t *testing.T) {
+	runEventTests(t, "/radarr", []eventTest{
+		{
+			fixture: "radarr_grab.json", wantChannel: "events",
+			wantTitle: "Dune: Part Two (2024)", wantAuthor: "📥 Grabbed",
+			wantFields: map[string]string{
+				"Quality": "Bluray-1080p", "Release group": "FLUX", "Indexer": "NZBgeek", "Size": "14.0 GB",
+				"Download client": "SABnzbd", "Release": "Dune.Part.Two.2024.1080p.BluRay.DDP5.1.x264-FLUX",
+			},
+		},
+		{
+			fixture: "radarr_download.json", wantChannel: "movies",
+			wantTitle: "Dune: Part Two (2024)", wantAuthor: "✅ Downloaded",
+			wantFields: map[string]string{
+				"Quality": "Bluray-1080p", "Release group": "FLUX", "Size": "14.0 GB",
+				"File": "Dune Part Two (2024) Bluray-1080p.mkv",
+			},
+		},
+		{
+			fixture: "radarr_test.json", wantChannel: "events",
+			wantTitle: "Test Title (1970)", wantAuthor: "🔔 Test",
+		},
+	})
+}
+
+func TestInvalidPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"not JSON", "not json"},
+		{"no event type", `{"series": {"title": "Severance"}}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, sent := newTestServer()
+			response := send(t, server, "/sonarr", []byte(test.payload), headerSecret(testSecret))
+			if response.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", response.Code, http.StatusBadRequest)
+			}
+			if len(*sent) != 0 {
+				t.Errorf("invalid payload posted %d embeds", len(*sent))
+			}
+		})
+	}
+}
+
+func TestSonarrListener(t *testing.T) {
+	server, _ := newTestServer()
+
+	var kinds []string
+	server.OnSonarrEvent(func(event SonarrEvent) { kinds = append(kinds, event.Kind()) })
+
+	send(t, server, "/sonarr", readFixture(t, "upgrade.json"), headerSecret(testSecret))
+	if len(kinds) != 1 || kinds[0] != "upgrade" {
+		t.Errorf("Sonarr listener got %v, want [upgrade]", kinds)
+	}
+}
+
+func TestRadarrListener(t *testing.T) {
+	server, _ := newTestServer()
+
+	var kinds []string
+	server.OnRadarrEvent(func(event RadarrEvent) { kinds = append(kinds, event.Kind()) })
+
+	send(t, server, "/radarr", readFixture(t, "radarr_download.json"), headerSecret(testSecret))
+	if len(kinds) != 1 || kinds[0] != "download" {
+		t.Errorf("Radarr listener got %v, want [download]", kinds)
+	}
+}
+
+func TestListenersRunWhenPostingFails(t *testing.T) {
+	server := NewServer(auth.WebhookConfig{DefaultChannel: "events"}, testSecret, func(string, *discordgo.MessageEmbed) error {
+		return errors.New("Discord is down")
+	})
+
+	var sonarrKinds, radarrKinds []string
+	server.OnSonarrEvent(func(event SonarrEvent) { sonarrKinds = append(sonarrKinds, event.Kind()) })
+	server.OnRadarrEvent(func(event RadarrEvent) { radarrKinds = append(radarrKinds, event.Kind()) })
+
+	for _, request := range []struct{ path, fixture string }{
+		{"/sonarr", "download.json"},
+		{"/radarr", "radarr_download.json"},
+	} {
+		response := send(t, server, request.path, readFixture(t, request.fixture), headerSecret(testSecret))
+		if response.Code != http.StatusBadGateway {
+			t.Errorf("%s status = %d, want %d", request.path, response.Code, http.StatusBadGateway)
+		}
+	}
+	if len(sonarrKinds) != 1 || len(radarrKinds) != 1 {
+		t.Errorf("listeners got %v and %v, want the download events", sonarrKinds, radarrKinds)
+	}
+}